@@ -0,0 +1,186 @@
+package forth
+
+import (
+	"errors"
+	"testing"
+)
+
+var arithmeticTestGroups = []testGroup{
+	{
+		group: "mod",
+		tests: []testCase{
+			{
+				"gives the remainder of dividing two numbers",
+				[]string{"13 4 mod"},
+				[]int{1},
+			},
+			{
+				"truncates, so the remainder takes the sign of the dividend",
+				[]string{"-7 2 mod"},
+				[]int{-1},
+			},
+			{
+				"truncates with a negative divisor",
+				[]string{"7 -2 mod"},
+				[]int{1},
+			},
+			{
+				"truncates with both numbers negative",
+				[]string{"-7 -2 mod"},
+				[]int{-1},
+			},
+			{
+				"is case-insensitive",
+				[]string{"13 4 MOD"},
+				[]int{1},
+			},
+			{
+				"errors if dividing by zero",
+				[]string{"4 0 mod"},
+				[]int(nil),
+			},
+			{
+				"errors if there is only one value on the stack",
+				[]string{"1 mod"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "/mod",
+		tests: []testCase{
+			{
+				"leaves the remainder below the quotient",
+				[]string{"13 4 /mod"},
+				[]int{1, 3},
+			},
+			{
+				"agrees with / and mod for negative numbers",
+				[]string{"-7 2 /mod", "-7 2 mod -7 2 /"},
+				[]int{-1, -3, -1, -3},
+			},
+			{
+				"truncates with a negative divisor",
+				[]string{"7 -2 /mod"},
+				[]int{1, -3},
+			},
+			{
+				"errors if dividing by zero",
+				[]string{"4 0 /mod"},
+				[]int(nil),
+			},
+			{
+				"errors if there is only one value on the stack",
+				[]string{"1 /mod"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "negate",
+		tests: []testCase{
+			{
+				"changes the sign of a positive number",
+				[]string{"5 negate"},
+				[]int{-5},
+			},
+			{
+				"changes the sign of a negative number",
+				[]string{"-5 negate"},
+				[]int{5},
+			},
+			{
+				"leaves zero alone",
+				[]string{"0 negate"},
+				[]int{0},
+			},
+			{
+				"errors if there is nothing on the stack",
+				[]string{"negate"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "abs",
+		tests: []testCase{
+			{
+				"leaves a positive number alone",
+				[]string{"5 abs"},
+				[]int{5},
+			},
+			{
+				"makes a negative number positive",
+				[]string{"-5 abs"},
+				[]int{5},
+			},
+			{
+				"errors if there is nothing on the stack",
+				[]string{"abs"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "min",
+		tests: []testCase{
+			{
+				"keeps the smaller of two numbers",
+				[]string{"3 -4 min", "-4 3 min"},
+				[]int{-4, -4},
+			},
+			{
+				"keeps one copy of equal numbers",
+				[]string{"2 2 min"},
+				[]int{2},
+			},
+			{
+				"leaves values below the top two alone",
+				[]string{"9 3 4 min"},
+				[]int{9, 3},
+			},
+			{
+				"errors if there is only one value on the stack",
+				[]string{"1 min"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "max",
+		tests: []testCase{
+			{
+				"keeps the larger of two numbers",
+				[]string{"3 -4 max", "-4 3 max"},
+				[]int{3, 3},
+			},
+			{
+				"leaves values below the top two alone",
+				[]string{"9 3 4 max"},
+				[]int{9, 4},
+			},
+			{
+				"errors if there is only one value on the stack",
+				[]string{"1 max"},
+				[]int(nil),
+			},
+		},
+	},
+}
+
+func TestArithmeticWords(t *testing.T) {
+	runTestGroups(t, arithmeticTestGroups)
+}
+
+func TestDivisionByZero(t *testing.T) {
+	for _, input := range []string{"4 0 /", "4 0 mod", "4 0 /mod"} {
+		in := New()
+		if err := in.Eval(input); !errors.Is(err, ErrDivideByZero) {
+			t.Fatalf("FAIL: Eval(%q) expected ErrDivideByZero, got %v", input, err)
+		}
+		if v := in.Stack(); len(v) != 0 {
+			t.Fatalf("FAIL: Eval(%q) expected both operands dropped, got %v", input, v)
+		}
+		t.Logf("PASS: %s", input)
+	}
+}
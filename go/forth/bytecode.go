@@ -0,0 +1,376 @@
+package forth
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// opcode says what a bytecode instruction does with its argument.
+type opcode byte
+
+const (
+	opPush          opcode = iota // push arg
+	opPushBig                     // push bigs[arg]
+	opCall                        // call the word names[arg]
+	opCallDef                     // call defs[arg], the definition being made, for RECURSE
+	opDefine                      // define defs[arg]
+	opBranch                      // jump to arg
+	opBranchIfFalse               // pop a flag and jump to arg if it is false
+	opDo                          // start a DO LOOP from a limit and start index
+	opLoop                        // finish a pass of a DO LOOP, jumping to arg for the next
+	opPrint                       // print strings[arg]
+	opString                      // push the address and length of strings[arg]
+	opSee                         // print how names[arg] was defined
+	opForget                      // forget names[arg]
+	opInclude                     // execute the file names[arg]
+	opBegin                       // start a BEGIN loop, which does nothing but report a trace
+	opcodeCount
+)
+
+// instruction is a single step of bytecode. Branches jump to an index in
+// the same block of code. Every instruction, branches included, counts as
+// a step, so a loop can always be stopped by WithMaxSteps or a context.
+type instruction struct {
+	op    opcode
+	arg   int
+	pos   int  // index of the token the instruction was compiled from
+	quiet bool // not reported to WithTrace, as Eval doesn't report the word
+}
+
+// defKind is the defining word that made a definition.
+type defKind byte
+
+const (
+	colonDef defKind = iota
+	variableDef
+	constantDef
+	defKindCount
+)
+
+// definition is a word defined by a Program.
+type definition struct {
+	kind   defKind
+	name   string
+	source string
+	code   []instruction // the body of a colon definition
+}
+
+// Program is forth code compiled to bytecode by Compile. Running it does
+// what evaluating the code would, without lexing, folding case or parsing
+// numbers again. A Program can be run any number of times, against any
+// Interpreter, and saved with MarshalBinary.
+//
+// Eval doesn't go through a Program. It runs each word as soon as it has
+// been read, so the words before a malformed definition take effect, as
+// they must at a prompt, while Compile rejects the whole of the code. And
+// its compiled definitions, closures calling closures, run loops faster
+// than a Program does. A Program pays off on code that runs more than
+// once, where it skips reading the code again. The two give the same
+// results, errors and TraceEvents for the same code, which the tests check
+// case by case.
+type Program struct {
+	tokens  []token // the source, for positions in errors and traces
+	names   []string
+	bigs    []*big.Int
+	strings []string
+	defs    []definition
+	main    []instruction
+}
+
+// Compile turns lines of forth code into a Program.
+//
+// Words are looked up by name when Eval would look them up: those in a
+// definition when the definition is made, so redefining a word later
+// doesn't change what earlier definitions call, and the rest each time
+// they run. So a Program may use words defined earlier in the session it
+// runs in, or registered with RegisterWord, and an unknown word is
+// reported when it runs. INCLUDE, too, reads its file when it runs, from
+// the file system of the Interpreter running the Program.
+//
+// Only the options that change how code is read, such as
+// WithCaseSensitive, matter to Compile. The rest are ignored.
+func Compile(code []string, options ...Option) (*Program, error) {
+	var settings Interpreter
+	for _, option := range options {
+		option(&settings)
+	}
+	c := compiler{
+		p:             &Program{tokens: lex(code)},
+		names:         map[string]int{},
+		current:       -1,
+		caseSensitive: settings.caseSensitive,
+	}
+	tokens := c.p.tokens
+	for pos := 0; pos < len(tokens); pos++ {
+		if err := c.compileTop(&pos); err != nil {
+			return nil, positioned(err, pos, tokens)
+		}
+	}
+	return c.p, nil
+}
+
+// compiler builds a Program, keeping track of the names it uses.
+type compiler struct {
+	p       *Program
+	names   map[string]int // indexes into p.names
+	current int            // the colon definition being compiled, for RECURSE
+
+	caseSensitive bool
+}
+
+// fold returns the key a word is looked up by, as Interpreter.fold does.
+func (c *compiler) fold(word string) string {
+	return foldCase(word, c.caseSensitive)
+}
+
+// compileTop compiles the token at *pos outside of any definition.
+// Defining words consume the tokens that make up the definition and
+// advance *pos past them.
+func (c *compiler) compileTop(pos *int) error {
+	tokens := c.p.tokens
+	start := *pos
+	word := c.fold(tokens[start].text)
+	switch {
+	case word == ":":
+		return c.compileColon(pos)
+	case word == "variable", word == "constant":
+		name, err := definedName(pos, tokens, c.caseSensitive)
+		if err != nil {
+			return err
+		}
+		kind := variableDef
+		if word == "constant" {
+			kind = constantDef
+		}
+		c.emitDefine(definition{kind: kind, name: name}, *pos)
+		return nil
+	case word == "see", word == "forget":
+		name, err := parsedName(pos, tokens)
+		if err != nil {
+			return err
+		}
+		op := opSee
+		if word == "forget" {
+			op = opForget
+		}
+		c.p.main = append(c.p.main, instruction{op: op, arg: c.name(name), pos: *pos, quiet: true})
+		return nil
+	case word == "include":
+		name, err := parsedName(pos, tokens)
+		if err != nil {
+			return err
+		}
+		c.p.main = append(c.p.main, instruction{op: opInclude, arg: c.name(name), pos: *pos, quiet: true})
+		return nil
+	case word == "char":
+		code, err := parsedChar(pos, tokens)
+		if err != nil {
+			return err
+		}
+		c.p.main = append(c.p.main, instruction{op: opPush, arg: code, pos: *pos, quiet: true})
+		return nil
+	case controlWords[word]:
+		return fmt.Errorf("%s is %w", tokens[start].text, ErrCompileOnly)
+	}
+	ins, err := c.compileWord(tokens, start)
+	if err != nil {
+		return err
+	}
+	c.p.main = append(c.p.main, ins)
+	return nil
+}
+
+// compileColon compiles `: name body ;` into a definition, just as
+// assignStmt does.
+func (c *compiler) compileColon(pos *int) error {
+	tokens := c.p.tokens
+	start := *pos
+	name, end, err := colonDefinition(pos, tokens, c.caseSensitive)
+	if err != nil {
+		return err
+	}
+
+	c.current = len(c.p.defs)
+	c.p.defs = append(c.p.defs, definition{kind: colonDef, name: name})
+	defer func() {
+		c.current = -1
+	}()
+	*pos++
+	code, _, err := c.compileBody(nil, tokens[:end], pos)
+	if err != nil {
+		return err
+	}
+	def := &c.p.defs[c.current]
+	def.code = code
+	def.source = source(tokens[start : end+1])
+	c.p.main = append(c.p.main, instruction{op: opDefine, arg: c.current, pos: start, quiet: true})
+	*pos = end
+	return nil
+}
+
+// compileBody appends the instructions for tokens starting at *pos to code
+// until one of the terminators is reached. It returns the code and the
+// terminator that was found, or an empty string if the tokens ran out first.
+func (c *compiler) compileBody(code []instruction, tokens []token, pos *int, terminators ...string) ([]instruction, string, error) {
+	for ; *pos < len(tokens); *pos++ {
+		start := *pos
+		word := c.fold(tokens[start].text)
+		for _, terminator := range terminators {
+			if word == terminator {
+				return code, word, nil
+			}
+		}
+
+		var err error
+		switch word {
+		case "if":
+			code, err = c.compileIf(code, tokens, pos)
+		case "do":
+			code, err = c.compileDo(code, tokens, pos)
+		case "begin":
+			code, err = c.compileBegin(code, tokens, pos)
+		case "i", "j", ">r", "r>", "r@":
+			code = append(code, instruction{op: opCall, arg: c.name(word), pos: start})
+		case "recurse":
+			code = append(code, instruction{op: opCallDef, arg: c.current, pos: start})
+		case "[char]":
+			var char int
+			if char, err = parsedChar(pos, tokens); err == nil {
+				code = append(code, instruction{op: opPush, arg: char, pos: start})
+			}
+		case ":":
+			err = ErrNestedDefinition
+		case "variable", "constant", "see", "forget", "char", "include":
+			err = fmt.Errorf("%s is %w", tokens[start].text, ErrInterpretOnly)
+		default:
+			if opener, ok := openingWords[word]; ok {
+				err = unbalanced(tokens[start].text + " without a matching " + opener)
+			} else {
+				var ins instruction
+				if ins, err = c.compileWord(tokens, start); err == nil {
+					code = append(code, ins)
+				}
+			}
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	return code, "", nil
+}
+
+// compileIf compiles `IF true-part [ELSE false-part] THEN` into branches.
+func (c *compiler) compileIf(code []instruction, tokens []token, pos *int) ([]instruction, error) {
+	branch := len(code)
+	code = append(code, instruction{op: opBranchIfFalse, pos: *pos})
+	*pos++
+	code, terminator, err := c.compileBody(code, tokens, pos, "else", "then")
+	if err != nil {
+		return nil, err
+	}
+	if terminator == "else" {
+		skip := len(code)
+		code = append(code, instruction{op: opBranch, pos: *pos, quiet: true})
+		code[branch].arg = len(code)
+		branch = skip
+		*pos++
+		code, terminator, err = c.compileBody(code, tokens, pos, "then")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if terminator != "then" {
+		return nil, unbalanced("if without a matching then")
+	}
+	code[branch].arg = len(code)
+	return code, nil
+}
+
+// compileDo compiles `DO body LOOP`.
+func (c *compiler) compileDo(code []instruction, tokens []token, pos *int) ([]instruction, error) {
+	code = append(code, instruction{op: opDo, pos: *pos})
+	body := len(code)
+	*pos++
+	code, terminator, err := c.compileBody(code, tokens, pos, "loop")
+	if err != nil {
+		return nil, err
+	}
+	if terminator != "loop" {
+		return nil, unbalanced("do without a matching loop")
+	}
+	return append(code, instruction{op: opLoop, arg: body, pos: *pos, quiet: true}), nil
+}
+
+// compileBegin compiles `BEGIN body UNTIL` and `BEGIN test WHILE body REPEAT`.
+// The loop starts with opBegin, which Eval reports to WithTrace once, so
+// each pass branches back to the instruction after it.
+func (c *compiler) compileBegin(code []instruction, tokens []token, pos *int) ([]instruction, error) {
+	code = append(code, instruction{op: opBegin, pos: *pos})
+	top := len(code)
+	*pos++
+	code, terminator, err := c.compileBody(code, tokens, pos, "until", "while")
+	if err != nil {
+		return nil, err
+	}
+	switch terminator {
+	case "until":
+		return append(code, instruction{op: opBranchIfFalse, arg: top, pos: *pos, quiet: true}), nil
+	case "while":
+		exit := len(code)
+		code = append(code, instruction{op: opBranchIfFalse, pos: *pos, quiet: true})
+		*pos++
+		code, terminator, err = c.compileBody(code, tokens, pos, "repeat")
+		if err != nil {
+			return nil, err
+		}
+		if terminator != "repeat" {
+			return nil, unbalanced("while without a matching repeat")
+		}
+		code = append(code, instruction{op: opBranch, arg: top, pos: *pos, quiet: true})
+		code[exit].arg = len(code)
+		return code, nil
+	}
+	return nil, unbalanced("begin without a matching until or repeat")
+}
+
+// compileWord compiles a string literal, a number, or a call to a word.
+func (c *compiler) compileWord(tokens []token, pos int) (instruction, error) {
+	t := tokens[pos]
+	word := c.fold(t.text)
+	if stringWords[word] {
+		if t.unclosed {
+			return instruction{}, fmt.Errorf("%s %w", t.text, ErrUnclosedString)
+		}
+		op := opPrint
+		if word == `s"` {
+			op = opString
+		}
+		c.p.strings = append(c.p.strings, t.quoted)
+		return instruction{op: op, arg: len(c.p.strings) - 1, pos: pos}, nil
+	}
+	if num, err := strconv.Atoi(word); err == nil {
+		return instruction{op: opPush, arg: num, pos: pos}, nil
+	} else if num, ok := parseBig(word, err); ok {
+		c.p.bigs = append(c.p.bigs, num)
+		return instruction{op: opPushBig, arg: len(c.p.bigs) - 1, pos: pos}, nil
+	}
+	return instruction{op: opCall, arg: c.name(word), pos: pos}, nil
+}
+
+// emitDefine adds a definition made by VARIABLE or CONSTANT.
+func (c *compiler) emitDefine(def definition, pos int) {
+	c.p.defs = append(c.p.defs, def)
+	c.p.main = append(c.p.main, instruction{op: opDefine, arg: len(c.p.defs) - 1, pos: pos, quiet: true})
+}
+
+// name returns the index of name in the program's names, adding it if it
+// isn't there yet.
+func (c *compiler) name(name string) int {
+	if i, ok := c.names[name]; ok {
+		return i
+	}
+	c.names[name] = len(c.p.names)
+	c.p.names = append(c.p.names, name)
+	return len(c.p.names) - 1
+}
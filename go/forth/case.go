@@ -0,0 +1,28 @@
+package forth
+
+import "strings"
+
+// WithCaseSensitive chooses whether words are case-sensitive. By default
+// they aren't, so DUP, Dup and dup are the same word. When they are, a word
+// must be spelled just as it was defined, and built-in words are spelled in
+// lower case. Either way the dictionary keeps the spelling each word was
+// defined with, and errors show words as they were written.
+func WithCaseSensitive(sensitive bool) Option {
+	return func(in *Interpreter) {
+		in.caseSensitive = sensitive
+	}
+}
+
+// fold returns the key a word is looked up by.
+func (in *Interpreter) fold(word string) string {
+	return foldCase(word, in.caseSensitive)
+}
+
+// foldCase returns the key a word is looked up by, which is the word
+// itself if words are case-sensitive, or the word in lower case if not.
+func foldCase(word string, caseSensitive bool) string {
+	if caseSensitive {
+		return word
+	}
+	return strings.ToLower(word)
+}
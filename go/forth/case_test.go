@@ -0,0 +1,142 @@
+package forth
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCaseSensitivity(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		sensitive   bool
+		input       []string
+		expected    []int // nil slice indicates error expected.
+	}{
+		{"insensitive built-ins", false, []string{"1 DUP Dup dup"}, []int{1, 1, 1, 1}},
+		{"insensitive user words", false, []string{": Foo 5 ;", "foo FOO"}, []int{5, 5}},
+		{"insensitive redefinition", false, []string{": foo 5 ;", ": FOO 6 ;", "foo"}, []int{6}},
+		{"insensitive control words", false, []string{": f IF 1 ELSE 2 THEN ;", "0 f"}, []int{2}},
+		{"sensitive built-ins", true, []string{"1 dup"}, []int{1, 1}},
+		{"sensitive upper-case built-ins are unknown", true, []string{"1 DUP"}, nil},
+		{"sensitive user words", true, []string{": Foo 5 ;", ": foo 6 ;", "Foo foo"}, []int{5, 6}},
+		{"sensitive misspelled user word", true, []string{": Foo 5 ;", "FOO"}, nil},
+		{"sensitive upper-case word overrides nothing", true, []string{": DUP 7 ;", "1 dup DUP"}, []int{1, 1, 7}},
+		{"sensitive upper-case control words are words", true, []string{": IF 3 ;", ": f IF ;", "f"}, []int{3}},
+		{"sensitive upper-case defining words are unknown", true, []string{"VARIABLE x"}, nil},
+		{"sensitive variables", true, []string{"variable X 4 X ! X @"}, []int{4}},
+		{"sensitive forget", true, []string{": a 1 ;", ": A 2 ;", "forget A", "a"}, []int{1}},
+	} {
+		in := New(WithCaseSensitive(tc.sensitive))
+		err := in.evalContext(context.Background(), lex(tc.input))
+		checkCase(t, "Eval", tc.description, tc.input, tc.expected, in.Stack(), err)
+
+		in = New(WithCaseSensitive(tc.sensitive))
+		p, err := Compile(tc.input, WithCaseSensitive(tc.sensitive))
+		if err == nil {
+			err = in.Run(p)
+		}
+		checkCase(t, "Compile", tc.description, tc.input, tc.expected, in.Stack(), err)
+	}
+}
+
+func checkCase(t *testing.T, how, description string, input []string, expected, v []int, err error) {
+	t.Helper()
+	if err == nil {
+		if expected == nil {
+			t.Fatalf("FAIL: %s | %s\n\t%#v expected an error, got %v", how, description, input, v)
+		} else if !reflect.DeepEqual(v, expected) {
+			t.Fatalf("FAIL: %s | %s\n\t%#v expected %v, got %v", how, description, input, expected, v)
+		}
+	} else if expected != nil {
+		t.Fatalf("FAIL: %s | %s\n\t%#v expected %v, got an error: %q", how, description, input, expected, err)
+	}
+	t.Logf("PASS: %s | %s", how, description)
+}
+
+func TestWordsKeepSpelling(t *testing.T) {
+	in := New()
+	if err := in.Eval(": Double 2 * ; VARIABLE Count"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	if words := in.Words(); !reflect.DeepEqual(words[:2], []string{"Count", "Double"}) {
+		t.Fatalf("expected Words to start with [Count Double], got %v", words[:2])
+	}
+	if text, err := in.See("DOUBLE"); err != nil || text != ": Double 2 * ;" {
+		t.Fatalf(`expected See("DOUBLE") to return ": Double 2 * ;", got %q, %v`, text, err)
+	}
+	if text, err := in.See("DUP"); err != nil || text != "DUP is built in" {
+		t.Fatalf(`expected See("DUP") to return "DUP is built in", got %q, %v`, text, err)
+	}
+
+	if err := in.RegisterWord("Sensor", func(*Interpreter) error { return nil }); err != nil {
+		t.Fatalf("RegisterWord returned an error: %q", err)
+	}
+	found := false
+	for _, word := range in.Words() {
+		found = found || word == "Sensor"
+	}
+	if !found {
+		t.Fatalf("expected Words to include Sensor, got %v", in.Words())
+	}
+}
+
+func TestErrorsKeepSpelling(t *testing.T) {
+	for _, tc := range []struct {
+		sensitive bool
+		input     string
+		message   string
+	}{
+		{false, "1 Bogus", "Bogus is not a built-in or recognized user-defined word"},
+		{true, "1 DUP", "DUP is not a built-in or recognized user-defined word"},
+		{false, "1 IF", "IF is only valid inside a word definition"},
+		{false, ": f Variable x ;", "Variable is only valid outside a word definition"},
+		{false, "SEE Nothing", "Nothing is not a built-in or recognized user-defined word"},
+		{false, "FORGET Dup", "Dup is built in and can't be forgotten"},
+		{false, "1 INCLUDE", "INCLUDE is missing a name"},
+		{false, "See", "See is missing a name"},
+	} {
+		err := New(WithCaseSensitive(tc.sensitive)).Eval(tc.input)
+		if message := unpositioned(err); message != tc.message {
+			t.Fatalf("Eval(%q) expected %q, got %v", tc.input, tc.message, err)
+		}
+		p, err := Compile([]string{tc.input}, WithCaseSensitive(tc.sensitive))
+		if err == nil {
+			err = New(WithCaseSensitive(tc.sensitive)).Run(p)
+		}
+		if message := unpositioned(err); message != tc.message {
+			t.Fatalf("Compile(%q) expected %q, got %v", tc.input, tc.message, err)
+		}
+	}
+}
+
+// unpositioned returns the message of the error wrapped by an EvalError.
+func unpositioned(err error) string {
+	var evalErr *EvalError
+	if !errors.As(err, &evalErr) {
+		return ""
+	}
+	return evalErr.Err.Error()
+}
+
+func TestCaseSensitiveRegisterWord(t *testing.T) {
+	in := New(WithCaseSensitive(true))
+	for _, name := range []string{"Sensor", "sensor", "IF"} {
+		value := len(name)
+		if err := in.RegisterWord(name, func(in *Interpreter) error {
+			return in.Push(value)
+		}); err != nil {
+			t.Fatalf("RegisterWord(%q) returned an error: %q", name, err)
+		}
+	}
+	if err := in.Eval("Sensor sensor IF"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{6, 6, 2}) {
+		t.Fatalf("expected [6 6 2], got %v", v)
+	}
+	if err := in.RegisterWord("if", nil); !errors.Is(err, ErrInvalidName) {
+		t.Fatalf(`RegisterWord("if") expected ErrInvalidName, got %v`, err)
+	}
+}
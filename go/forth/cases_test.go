@@ -121,6 +121,41 @@ var testGroups = []testGroup{
 			},
 		},
 	},
+	{
+		group: "operators on deeper stacks",
+		tests: []testCase{
+			{
+				"addition only uses the top two values",
+				[]string{"1 2 3 +"},
+				[]int{1, 5},
+			},
+			{
+				"subtraction only uses the top two values",
+				[]string{"1 2 3 -"},
+				[]int{1, -1},
+			},
+			{
+				"multiplication only uses the top two values",
+				[]string{"1 2 3 *"},
+				[]int{1, 6},
+			},
+			{
+				"division only uses the top two values",
+				[]string{"1 12 3 /"},
+				[]int{1, 4},
+			},
+			{
+				"division by zero errors with values below",
+				[]string{"1 4 0 /"},
+				[]int(nil),
+			},
+			{
+				"operators can be chained on a deep stack",
+				[]string{"1 2 3 4 + * -"},
+				[]int{-13},
+			},
+		},
+	},
 	{
 		group: "combined arithmetic",
 		tests: []testCase{
@@ -254,6 +289,16 @@ var testGroups = []testGroup{
 				[]string{": + * ;", "3 4 +"},
 				[]int{12},
 			},
+			{
+				"can use different words with the same name",
+				[]string{": foo 5 ;", ": bar foo ;", ": foo 6 ;", "bar foo"},
+				[]int{5, 6},
+			},
+			{
+				"can define word that uses word with the same name",
+				[]string{": foo 10 ;", ": foo foo 1 + ;", "foo"},
+				[]int{11},
+			},
 			{
 				"cannot redefine numbers",
 				[]string{": 1 2 ;"},
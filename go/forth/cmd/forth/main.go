@@ -0,0 +1,170 @@
+// Command forth is an interactive forth interpreter.
+//
+// Usage:
+//
+//	forth [-trace] [script ...]
+//	forth -o file script ...
+//
+// Each script is loaded in order, then lines are read from standard input
+// and evaluated against the same session. After each line the interpreter
+// prints `ok` and the stack. `WORDS` lists the dictionary and `SEE name`
+// shows how a word was defined. `INCLUDE path` runs another script, found
+// next to the script that includes it, or in the current directory when
+// typed at the prompt. It can't read files outside of the current
+// directory, not even through a symbolic link.
+//
+// With -trace, each word is printed to standard error before it executes,
+// along with where it was written and the stack it sees.
+//
+// With -o, the scripts are compiled to bytecode and saved to file instead
+// of being run. A saved file can be loaded like any other script, and runs
+// without being parsed again.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/topfunky/exercism-projects/go/forth"
+)
+
+func main() {
+	trace := flag.Bool("trace", false, "print each word and the stack before it executes")
+	output := flag.String("o", "", "compile the scripts to `file` instead of running them")
+	flag.Parse()
+
+	var err error
+	if *output != "" {
+		err = compile(flag.Args(), *output)
+	} else {
+		var options []forth.Option
+		if *trace {
+			options = append(options, forth.WithTrace(traceTo(os.Stderr)))
+		}
+		err = run(".", flag.Args(), os.Stdin, os.Stdout, options...)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "forth:", err)
+		os.Exit(1)
+	}
+}
+
+// run loads the scripts, then evaluates each line read from r, writing
+// program output and prompts to w. INCLUDE reads files from dir, unless it
+// is empty. A script inside dir is loaded as INCLUDE would load it, so the
+// files it includes are found next to it and its errors name it.
+func run(dir string, scripts []string, r io.Reader, w io.Writer, options ...forth.Option) error {
+	options = append([]forth.Option{forth.WithOutput(w)}, options...)
+	if dir != "" {
+		root, err := os.OpenRoot(dir)
+		if err != nil {
+			return err
+		}
+		defer root.Close()
+		options = append([]forth.Option{forth.WithFS(root.FS())}, options...)
+	}
+	in := forth.New(options...)
+	for _, script := range scripts {
+		if err := load(in, dir, script); err != nil {
+			return err
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if err := in.Eval(scanner.Text()); err != nil {
+			fmt.Fprintln(w, "error:", err)
+			continue
+		}
+		fmt.Fprintln(w, "ok", formatStack(in.Stack()))
+	}
+	return scanner.Err()
+}
+
+// load runs a script, which may be a compiled program.
+func load(in *forth.Interpreter, dir, script string) error {
+	code, err := os.ReadFile(script)
+	if err != nil {
+		return err
+	}
+	if program := new(forth.Program); program.UnmarshalBinary(code) == nil {
+		err = in.Run(program)
+	} else if name, ok := inside(dir, script); ok {
+		// EvalFile's errors name the file already.
+		return in.EvalFile(name)
+	} else {
+		err = in.EvalReader(bytes.NewReader(code))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", script, err)
+	}
+	return nil
+}
+
+// inside returns the slash-separated path of file relative to dir, if the
+// file is inside dir.
+func inside(dir, file string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absDir, absFile)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// compile compiles the scripts together and saves the program to output.
+func compile(scripts []string, output string) error {
+	var code []string
+	for _, script := range scripts {
+		text, err := os.ReadFile(script)
+		if err != nil {
+			return err
+		}
+		code = append(code, string(text))
+	}
+	program, err := forth.Compile(code)
+	if err != nil {
+		return err
+	}
+	data, err := program.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, data, 0644)
+}
+
+// formatStack shows the stack depth followed by its values, bottom first.
+func formatStack(stack []int) string {
+	text := fmt.Sprintf("<%d>", len(stack))
+	for _, value := range stack {
+		text += fmt.Sprintf(" %d", value)
+	}
+	return text
+}
+
+// traceTo returns a trace callback that writes one line per word to w.
+// Words from included files are prefixed with the file's name.
+func traceTo(w io.Writer) func(forth.TraceEvent) {
+	return func(e forth.TraceEvent) {
+		at := fmt.Sprintf("%d:%d", e.Line, e.Column)
+		if e.File != "" {
+			at = e.File + ":" + at
+		}
+		fmt.Fprintf(w, "trace: %s %s %s\n", at, e.Word, formatStack(e.Stack))
+	}
+}
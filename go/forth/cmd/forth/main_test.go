@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/topfunky/exercism-projects/go/forth"
+)
+
+func TestRun(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       string
+		output      string
+	}{
+		{
+			"prints ok and the stack after each line",
+			"1 2\n+\n",
+			"ok <2> 1 2\nok <1> 3\n",
+		},
+		{
+			"keeps definitions between lines",
+			": sq dup * ;\n4 sq\n",
+			"ok <0>\nok <1> 16\n",
+		},
+		{
+			"prints program output before ok",
+			"42 .\n",
+			"42 ok <0>\n",
+		},
+		{
+			"reports errors and keeps going",
+			"foo\n1\n",
+			"error: line 1, column 1: foo is not a built-in or recognized user-defined word\nok <1> 1\n",
+		},
+		{
+			"shows a definition with SEE",
+			": sq dup * ;\nSEE sq\n",
+			"ok <0>\n: sq dup * ;\nok <0>\n",
+		},
+		{
+			"lists user-defined words first with WORDS",
+			": sq dup * ;\nwords\n",
+			"ok <0>\nsq ",
+		},
+	} {
+		var out bytes.Buffer
+		if err := run("", nil, strings.NewReader(tc.input), &out); err != nil {
+			t.Fatalf("FAIL: %s\n\trun returned an error: %q", tc.description, err)
+		}
+		if !strings.HasPrefix(out.String(), tc.output) {
+			t.Fatalf("FAIL: %s\n\tinput %q expected output %q, got %q",
+				tc.description, tc.input, tc.output, out.String())
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
+
+func TestRunLoadsScripts(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "square.fs")
+	if err := os.WriteFile(script, []byte(": sq\n  dup * ;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := run("", []string{script}, strings.NewReader("3 sq\n"), &out); err != nil {
+		t.Fatalf("run returned an error: %q", err)
+	}
+	if out.String() != "ok <1> 9\n" {
+		t.Fatalf("expected %q, got %q", "ok <1> 9\n", out.String())
+	}
+
+	if err := run("", []string{script + ".missing"}, strings.NewReader(""), &out); err == nil {
+		t.Fatalf("expected an error for a missing script")
+	}
+}
+
+func TestRunTraces(t *testing.T) {
+	var out, trace bytes.Buffer
+	if err := run("", nil, strings.NewReader("1 2 +\n"), &out, forth.WithTrace(traceTo(&trace))); err != nil {
+		t.Fatalf("run returned an error: %q", err)
+	}
+	if out.String() != "ok <1> 3\n" {
+		t.Fatalf("expected output %q, got %q", "ok <1> 3\n", out.String())
+	}
+	expected := "trace: 1:1 1 <0>\ntrace: 1:3 2 <1> 1\ntrace: 1:5 + <2> 1 2\n"
+	if trace.String() != expected {
+		t.Fatalf("expected trace %q, got %q", expected, trace.String())
+	}
+}
+
+func TestRunIncludes(t *testing.T) {
+	fsys := fstest.MapFS{"lib/square.fs": {Data: []byte(": sq\n  dup * ;\n")}}
+	var out, trace bytes.Buffer
+	err := run("", nil, strings.NewReader("include lib/square.fs\n3 sq\n"), &out,
+		forth.WithFS(fsys), forth.WithTrace(traceTo(&trace)))
+	if err != nil {
+		t.Fatalf("run returned an error: %q", err)
+	}
+	if out.String() != "ok <0>\nok <1> 9\n" {
+		t.Fatalf("expected output %q, got %q", "ok <0>\nok <1> 9\n", out.String())
+	}
+	expected := "trace: 1:1 3 <0>\ntrace: 1:3 sq <1> 3\n" +
+		"trace: lib/square.fs:2:3 dup <1> 3\ntrace: lib/square.fs:2:7 * <2> 3 3\n"
+	if trace.String() != expected {
+		t.Fatalf("expected trace %q, got %q", expected, trace.String())
+	}
+}
+
+func TestRunIncludesFromScripts(t *testing.T) {
+	dir := t.TempDir()
+	for name, code := range map[string]string{
+		"lib/main.fs": "include util.fs\n: quad twice twice ;\n",
+		"lib/util.fs": ": twice 2 * ;\n",
+		"lib/bad.fs":  "1\n  bogus\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	script := filepath.Join(dir, "lib", "main.fs")
+	if err := run(dir, []string{script}, strings.NewReader("3 quad\n"), &out); err != nil {
+		t.Fatalf("run returned an error: %q", err)
+	}
+	if out.String() != "ok <1> 12\n" {
+		t.Fatalf("expected %q, got %q", "ok <1> 12\n", out.String())
+	}
+
+	err := run(dir, []string{filepath.Join(dir, "lib", "bad.fs")}, strings.NewReader(""), &out)
+	expected := "lib/bad.fs, line 2, column 3: bogus is not a built-in or recognized user-defined word"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+}
+
+func TestRunKeepsIncludeInTheDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "secret.fs"), []byte("42\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sandbox := filepath.Join(dir, "sandbox")
+	if err := os.Mkdir(sandbox, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../secret.fs", filepath.Join(sandbox, "link.fs")); err != nil {
+		t.Skipf("can't make a symbolic link: %v", err)
+	}
+	var out bytes.Buffer
+	if err := run(sandbox, nil, strings.NewReader("include link.fs\n"), &out); err != nil {
+		t.Fatalf("run returned an error: %q", err)
+	}
+	if !strings.HasPrefix(out.String(), "error: ") {
+		t.Fatalf("expected INCLUDE to fail to follow a link out of the directory, got %q", out.String())
+	}
+}
+
+func TestCompileScripts(t *testing.T) {
+	dir := t.TempDir()
+	square := filepath.Join(dir, "square.fs")
+	if err := os.WriteFile(square, []byte(": sq dup * ;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cube := filepath.Join(dir, "cube.fs")
+	if err := os.WriteFile(cube, []byte(": cube dup sq * ;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	compiled := filepath.Join(dir, "cube.fc")
+	if err := compile([]string{square, cube}, compiled); err != nil {
+		t.Fatalf("compile returned an error: %q", err)
+	}
+
+	var out bytes.Buffer
+	if err := run("", []string{compiled}, strings.NewReader("3 cube\n"), &out); err != nil {
+		t.Fatalf("run returned an error: %q", err)
+	}
+	if out.String() != "ok <1> 27\n" {
+		t.Fatalf("expected %q, got %q", "ok <1> 27\n", out.String())
+	}
+
+	bad := filepath.Join(dir, "bad.fs")
+	if err := os.WriteFile(bad, []byte(": f if ;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := compile([]string{bad}, filepath.Join(dir, "bad.fc")); err == nil {
+		t.Fatalf("expected an error compiling an unbalanced definition")
+	}
+}
@@ -0,0 +1,92 @@
+package forth
+
+import "math/big"
+
+// Forth represents true as -1 (all bits set) and false as 0.
+const (
+	trueFlag  = -1
+	falseFlag = 0
+)
+
+// flag converts a Go bool to a Forth truth value.
+func flag(b bool) int {
+	if b {
+		return trueFlag
+	}
+	return falseFlag
+}
+
+func equalOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		return stk.Push(flag(stk.popCompare() == 0))
+	}
+	return underflow("found a single '=', did you mean to prepend some numbers?")
+}
+
+func lessThanOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		return stk.Push(flag(stk.popCompare() < 0))
+	}
+	return underflow("found a single '<', did you mean to prepend some numbers?")
+}
+
+func greaterThanOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		return stk.Push(flag(stk.popCompare() > 0))
+	}
+	return underflow("found a single '>', did you mean to prepend some numbers?")
+}
+
+func zeroEqualOp(stk *intStack) error {
+	if stk.Len() >= 1 {
+		zero := stk.isZero(0)
+		stk.Pop()
+		return stk.Push(flag(zero))
+	}
+	return underflow("can't compare with 0= without an argument")
+}
+
+func andOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		return stk.binaryOp(bitwiseInts(andInts), andBigs)
+	}
+	return underflow("found a single 'and', did you mean to prepend some numbers?")
+}
+
+func orOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		return stk.binaryOp(bitwiseInts(orInts), orBigs)
+	}
+	return underflow("found a single 'or', did you mean to prepend some numbers?")
+}
+
+func invertOp(stk *intStack) error {
+	if stk.Len() >= 1 {
+		return stk.unaryOp(invertInt, invertBig)
+	}
+	return underflow("can't invert without an argument")
+}
+
+func andInts(a, b int) int {
+	return a & b
+}
+
+func orInts(a, b int) int {
+	return a | b
+}
+
+func invertInt(a int) (int, bool) {
+	return ^a, false
+}
+
+func andBigs(a, b *big.Int) *big.Int {
+	return new(big.Int).And(a, b)
+}
+
+func orBigs(a, b *big.Int) *big.Int {
+	return new(big.Int).Or(a, b)
+}
+
+func invertBig(a *big.Int) *big.Int {
+	return new(big.Int).Not(a)
+}
@@ -0,0 +1,115 @@
+package forth
+
+import "testing"
+
+var compareTestGroups = []testGroup{
+	{
+		group: "comparison",
+		tests: []testCase{
+			{
+				"= is true for equal numbers",
+				[]string{"2 2 ="},
+				[]int{-1},
+			},
+			{
+				"= is false for different numbers",
+				[]string{"2 3 ="},
+				[]int{0},
+			},
+			{
+				"< compares the second value to the top value",
+				[]string{"2 3 <"},
+				[]int{-1},
+			},
+			{
+				"< is false for equal numbers",
+				[]string{"3 3 <"},
+				[]int{0},
+			},
+			{
+				"> compares the second value to the top value",
+				[]string{"2 3 >"},
+				[]int{0},
+			},
+			{
+				"> is true for a larger second value",
+				[]string{"-1 -5 >"},
+				[]int{-1},
+			},
+			{
+				"0= is true for zero",
+				[]string{"0 0="},
+				[]int{-1},
+			},
+			{
+				"0= is false for non-zero",
+				[]string{"7 0="},
+				[]int{0},
+			},
+			{
+				"can feed a conditional",
+				[]string{": sign dup 0 < if drop -1 else 0 > if 1 else 0 then then ;", "-7 sign"},
+				[]int{-1},
+			},
+			{
+				"errors if there is nothing on the stack",
+				[]string{"="},
+				[]int(nil),
+			},
+			{
+				"errors if there is only one value on the stack",
+				[]string{"1 <"},
+				[]int(nil),
+			},
+			{
+				"0= errors if there is nothing on the stack",
+				[]string{"0="},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "boolean",
+		tests: []testCase{
+			{
+				"and is bitwise",
+				[]string{"12 10 and"},
+				[]int{8},
+			},
+			{
+				"or is bitwise",
+				[]string{"12 10 or"},
+				[]int{14},
+			},
+			{
+				"invert flips true to false",
+				[]string{"-1 invert"},
+				[]int{0},
+			},
+			{
+				"invert flips false to true",
+				[]string{"0 INVERT"},
+				[]int{-1},
+			},
+			{
+				"and errors if there is only one value on the stack",
+				[]string{"1 and"},
+				[]int(nil),
+			},
+			{
+				"or errors if there is nothing on the stack",
+				[]string{"or"},
+				[]int(nil),
+			},
+			{
+				"invert errors if there is nothing on the stack",
+				[]string{"invert"},
+				[]int(nil),
+			},
+		},
+	},
+}
+
+func TestCompareWords(t *testing.T) {
+	runTestGroups(t, compareTestGroups)
+}
@@ -0,0 +1,255 @@
+package forth
+
+import "fmt"
+
+// controlWords are only meaningful inside a word definition, where they are
+// compiled into branches and loops.
+var controlWords = map[string]bool{
+	"if": true, "else": true, "then": true,
+	"do": true, "loop": true, "i": true, "j": true,
+	"begin": true, "until": true, "while": true, "repeat": true,
+	">r": true, "r>": true, "r@": true, "recurse": true,
+	"[char]": true,
+}
+
+// openingWords maps each closing control word to the word that must come
+// before it.
+var openingWords = map[string]string{
+	"else":   "if",
+	"then":   "if",
+	"loop":   "do",
+	"until":  "begin",
+	"while":  "begin",
+	"repeat": "begin",
+}
+
+// compiledOps are the control words that compile to a plain operation.
+var compiledOps = map[string]operation{
+	"i":  loopIndexOp(0),
+	"j":  loopIndexOp(1),
+	">r": toReturnOp,
+	"r>": fromReturnOp,
+	"r@": copyReturnOp,
+}
+
+// loopFrame tracks the index and limit of a running DO LOOP.
+type loopFrame struct {
+	index, limit int
+}
+
+// compileBody compiles tokens starting at *pos until one of the terminators
+// is reached. It returns the compiled operations and the terminator that was
+// found, or an empty string if the tokens ran out first.
+func (in *Interpreter) compileBody(tokens []token, pos *int, terminators ...string) ([]operation, string, error) {
+	var body []operation
+	for ; *pos < len(tokens); *pos++ {
+		start := *pos
+		word := in.fold(tokens[*pos].text)
+		for _, terminator := range terminators {
+			if word == terminator {
+				return body, word, nil
+			}
+		}
+
+		var op operation
+		var err error
+		switch word {
+		case "if":
+			op, err = in.compileIf(tokens, pos)
+		case "do":
+			op, err = in.compileDo(tokens, pos)
+		case "begin":
+			op, err = in.compileBegin(tokens, pos)
+		case "i", "j", ">r", "r>", "r@":
+			op = compiledOps[word]
+		case "recurse":
+			op = in.recurse
+		case "[char]":
+			op, err = compileChar(tokens, pos)
+		case ":":
+			err = ErrNestedDefinition
+		case "variable", "constant", "see", "forget", "char", "include":
+			err = fmt.Errorf("%s is %w", tokens[*pos].text, ErrInterpretOnly)
+		default:
+			if opener, ok := openingWords[word]; ok {
+				err = unbalanced(tokens[*pos].text + " without a matching " + opener)
+			} else {
+				op, err = in.compileWord(tokens[*pos])
+			}
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		body = append(body, in.traced(op, tokens, start))
+	}
+	return body, "", nil
+}
+
+// compileIf compiles `IF true-part [ELSE false-part] THEN`.
+func (in *Interpreter) compileIf(tokens []token, pos *int) (operation, error) {
+	*pos++
+	whenTrue, terminator, err := in.compileBody(tokens, pos, "else", "then")
+	if err != nil {
+		return nil, err
+	}
+	var whenFalse []operation
+	if terminator == "else" {
+		*pos++
+		whenFalse, terminator, err = in.compileBody(tokens, pos, "then")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if terminator != "then" {
+		return nil, unbalanced("if without a matching then")
+	}
+	return func(in *Interpreter) error {
+		flag, err := popFlag(&in.stk, "if")
+		if err != nil {
+			return err
+		}
+		if flag {
+			return runAll(in, whenTrue)
+		}
+		return runAll(in, whenFalse)
+	}, nil
+}
+
+// compileDo compiles `DO body LOOP`. The loop takes a limit and a start
+// index from the stack and always runs the body at least once.
+// Like the other loops, each pass counts as a step towards WithMaxSteps.
+func (in *Interpreter) compileDo(tokens []token, pos *int) (operation, error) {
+	*pos++
+	body, terminator, err := in.compileBody(tokens, pos, "loop")
+	if err != nil {
+		return nil, err
+	}
+	if terminator != "loop" {
+		return nil, unbalanced("do without a matching loop")
+	}
+	return func(in *Interpreter) error {
+		if err := doOp(in); err != nil {
+			return err
+		}
+		defer func() {
+			in.loops = in.loops[:len(in.loops)-1]
+		}()
+		for {
+			if err := in.step(); err != nil {
+				return err
+			}
+			if err := runAll(in, body); err != nil {
+				return err
+			}
+			frame := &in.loops[len(in.loops)-1]
+			frame.index++
+			if frame.index >= frame.limit {
+				return nil
+			}
+		}
+	}, nil
+}
+
+// compileBegin compiles `BEGIN body UNTIL` and `BEGIN test WHILE body REPEAT`.
+func (in *Interpreter) compileBegin(tokens []token, pos *int) (operation, error) {
+	*pos++
+	test, terminator, err := in.compileBody(tokens, pos, "until", "while")
+	if err != nil {
+		return nil, err
+	}
+	switch terminator {
+	case "until":
+		return func(in *Interpreter) error {
+			for {
+				if err := in.step(); err != nil {
+					return err
+				}
+				if err := runAll(in, test); err != nil {
+					return err
+				}
+				done, err := popFlag(&in.stk, "until")
+				if err != nil || done {
+					return err
+				}
+			}
+		}, nil
+	case "while":
+		*pos++
+		body, terminator, err := in.compileBody(tokens, pos, "repeat")
+		if err != nil {
+			return nil, err
+		}
+		if terminator != "repeat" {
+			return nil, unbalanced("while without a matching repeat")
+		}
+		return func(in *Interpreter) error {
+			for {
+				if err := in.step(); err != nil {
+					return err
+				}
+				if err := runAll(in, test); err != nil {
+					return err
+				}
+				more, err := popFlag(&in.stk, "while")
+				if err != nil || !more {
+					return err
+				}
+				if err := runAll(in, body); err != nil {
+					return err
+				}
+			}
+		}, nil
+	}
+	return nil, unbalanced("begin without a matching until or repeat")
+}
+
+// doOp starts a DO LOOP, taking a limit and a start index from the stack.
+func doOp(in *Interpreter) error {
+	if in.stk.Len() < 2 {
+		return underflow("do needs a limit and a start index on the stack")
+	}
+	start, err := in.stk.PopInt()
+	if err != nil {
+		return err
+	}
+	limit, err := in.stk.PopInt()
+	if err != nil {
+		return err
+	}
+	in.loops = append(in.loops, loopFrame{index: start, limit: limit})
+	return nil
+}
+
+// loopIndexOp pushes the index of a running DO LOOP. A depth of 0 is the
+// innermost loop (I), 1 is the loop around it (J).
+func loopIndexOp(depth int) operation {
+	return func(in *Interpreter) error {
+		if len(in.loops) <= depth {
+			return ErrNotInLoop
+		}
+		return in.stk.Push(in.loops[len(in.loops)-1-depth].index)
+	}
+}
+
+// runAll executes compiled operations in order, stopping at the first error.
+func runAll(in *Interpreter, ops []operation) error {
+	for _, op := range ops {
+		if err := in.step(); err != nil {
+			return err
+		}
+		if err := op(in); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// popFlag pops a value for a conditional word. Any non-zero value is true.
+func popFlag(stk *intStack, word string) (bool, error) {
+	if stk.Len() < 1 {
+		return false, underflow(word + " needs a flag on the stack")
+	}
+	zero := stk.isZero(0)
+	stk.Pop()
+	return !zero, nil
+}
@@ -0,0 +1,155 @@
+package forth
+
+import "testing"
+
+var controlTestGroups = []testGroup{
+	{
+		group: "if else then",
+		tests: []testCase{
+			{
+				"runs the true branch for a non-zero flag",
+				[]string{": f if 1 else 2 then ;", "5 f"},
+				[]int{1},
+			},
+			{
+				"runs the else branch for a zero flag",
+				[]string{": f if 1 else 2 then ;", "0 f"},
+				[]int{2},
+			},
+			{
+				"skips the body without an else",
+				[]string{": f if 8 then ;", "0 f"},
+				[]int{},
+			},
+			{
+				"can be nested",
+				[]string{": f if if 1 else 2 then else 3 then ;", "1 1 f 0 1 f 0 0 f"},
+				[]int{1, 2, 0, 3},
+			},
+			{
+				"is case-insensitive",
+				[]string{": f IF 1 Else 2 THEN ;", "1 f"},
+				[]int{1},
+			},
+			{
+				"errors if there is no flag on the stack",
+				[]string{": f if 1 then ;", "f"},
+				[]int(nil),
+			},
+			{
+				"errors without a then",
+				[]string{": f if 1 ;"},
+				[]int(nil),
+			},
+			{
+				"errors on a then without an if",
+				[]string{": f 1 then ;"},
+				[]int(nil),
+			},
+			{
+				"errors on an else without an if",
+				[]string{": f 1 else 2 then ;"},
+				[]int(nil),
+			},
+			{
+				"errors outside of a definition",
+				[]string{"1 if 2 then"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "do loop",
+		tests: []testCase{
+			{
+				"pushes each index with i",
+				[]string{": f 4 0 do i loop ;", "f"},
+				[]int{0, 1, 2, 3},
+			},
+			{
+				"runs the body at least once",
+				[]string{": f 0 0 do 9 loop ;", "f"},
+				[]int{9},
+			},
+			{
+				"reads the outer index with j",
+				[]string{": f 2 0 do 3 1 do j i loop loop ;", "f"},
+				[]int{0, 1, 0, 2, 1, 1, 1, 2},
+			},
+			{
+				"errors without a loop",
+				[]string{": f 4 0 do i ;"},
+				[]int(nil),
+			},
+			{
+				"errors on a loop without a do",
+				[]string{": f i loop ;"},
+				[]int(nil),
+			},
+			{
+				"errors if there is no limit on the stack",
+				[]string{": f do i loop ;", "1 f"},
+				[]int(nil),
+			},
+			{
+				"errors when reading j inside a single loop",
+				[]string{": f 2 0 do j loop ;", "f"},
+				[]int(nil),
+			},
+			{
+				"errors on i outside of a definition",
+				[]string{"i"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "begin until",
+		tests: []testCase{
+			{
+				"repeats until the flag is non-zero",
+				[]string{": f begin 1 - dup if 0 else 1 then until ;", "3 f"},
+				[]int{0},
+			},
+			{
+				"errors without an until",
+				[]string{": f begin 1 ;"},
+				[]int(nil),
+			},
+			{
+				"errors on an until without a begin",
+				[]string{": f 1 until ;"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "begin while repeat",
+		tests: []testCase{
+			{
+				"runs the body while the flag is non-zero",
+				[]string{": f begin dup while 1 - repeat ;", "3 f"},
+				[]int{0},
+			},
+			{
+				"skips the body if the flag starts as zero",
+				[]string{": f begin 0 while 1 repeat ;", "f"},
+				[]int{},
+			},
+			{
+				"errors without a repeat",
+				[]string{": f begin 1 while 2 ;"},
+				[]int(nil),
+			},
+			{
+				"errors on a repeat without a while",
+				[]string{": f begin 1 repeat ;"},
+				[]int(nil),
+			},
+		},
+	},
+}
+
+func TestControlWords(t *testing.T) {
+	runTestGroups(t, controlTestGroups)
+}
@@ -0,0 +1,75 @@
+package forth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMalformedDefinitions(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       []string
+		target      error
+	}{
+		{"a colon on its own is missing a name", []string{":"}, ErrMissingName},
+		{"a definition with no name is missing a name", []string{": ;"}, ErrMissingName},
+		{"a definition with no name or body after values", []string{"1 2 :"}, ErrMissingName},
+		{"a definition without a semicolon", []string{": foo"}, ErrUnterminatedDefinition},
+		{"a definition with a body but no semicolon", []string{": foo 1 2 +"}, ErrUnterminatedDefinition},
+		{"a semicolon on a later line still ends a definition", []string{": foo", ";", ": bar"}, ErrUnterminatedDefinition},
+		{"a definition inside another", []string{": foo : bar 1 ; ;"}, ErrNestedDefinition},
+		{"a definition named with a colon", []string{": : 1 ;"}, ErrNestedDefinition},
+		{"a definition named with a parsing word", []string{": variable 1 ;"}, ErrInvalidName},
+		{"a definition named with a control word", []string{": IF 1 ;"}, ErrInvalidName},
+		{"a definition named with a string word", []string{`: ." 1 ;`}, ErrInvalidName},
+		{"a variable named with a semicolon", []string{"variable ;"}, ErrMissingName},
+		{"a constant on its own is missing a name", []string{"1 constant"}, ErrMissingName},
+		{"a variable named with a control word", []string{"variable then"}, ErrInvalidName},
+		{"a number can't be defined", []string{": 1 2 ;"}, ErrRedefineNumber},
+	} {
+		if _, err := Forth(tc.input); !errors.Is(err, tc.target) {
+			t.Fatalf("FAIL: %s\n\tForth(%#v) expected errors.Is(err, %q), got %v",
+				tc.description, tc.input, tc.target, err)
+		}
+		if _, err := Compile(tc.input); !errors.Is(err, tc.target) {
+			t.Fatalf("FAIL: %s\n\tCompile(%#v) expected errors.Is(err, %q), got %v",
+				tc.description, tc.input, tc.target, err)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
+
+func TestMalformedDefinitionPosition(t *testing.T) {
+	_, err := Forth([]string{"1 2", ": foo 3"})
+	var evalErr *EvalError
+	if !errors.As(err, &evalErr) {
+		t.Fatalf("expected an *EvalError, got %v", err)
+	}
+	if evalErr.Line != 2 || evalErr.Column != 3 {
+		t.Fatalf("expected the error at line 2, column 3, got line %d, column %d", evalErr.Line, evalErr.Column)
+	}
+}
+
+func FuzzDefinitions(f *testing.F) {
+	for _, seed := range []string{
+		":", ": ;", ": foo", ": foo ;", ": 1 ;", ": foo : bar ; ;", "1 2 :",
+		": foo 1 2 + ;", ": foo if ;", ": foo then ;", ": foo do i loop ; 3 0 foo",
+		": foo recurse ; foo", `: foo ." hi ;`, `: foo s" hi" type ; foo`,
+		"variable", "constant", "1 constant ;", "see", "forget", ": foo ; forget foo foo",
+		": foo [char] ;", "char", ": foo >r ; 1 foo", "2000000000000000000000A",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, code string) {
+		in := New(WithMaxSteps(10000), WithMaxStackDepth(1024), WithMemorySize(1024))
+		if err := in.Eval(code); err != nil {
+			var evalErr *EvalError
+			if !errors.As(err, &evalErr) {
+				t.Fatalf("Eval(%q) returned an error without a position: %v", code, err)
+			}
+		}
+		if p, err := Compile([]string{code}); err == nil {
+			New(WithMaxSteps(10000), WithMaxStackDepth(1024), WithMemorySize(1024)).Run(p)
+		}
+	})
+}
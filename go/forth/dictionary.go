@@ -0,0 +1,176 @@
+package forth
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// dictionary holds user-defined words in the order they were defined.
+// A newer definition hides an older one with the same name until it is
+// forgotten.
+type dictionary struct {
+	entries []entry
+	latest  map[string]int // index of the newest entry for each key
+}
+
+// entry is a single user-defined word.
+type entry struct {
+	key    string // the name as it is looked up
+	name   string // the name as it was spelled
+	op     operation
+	source string
+	here   int // size of memory before the word was defined
+}
+
+// lookup finds the newest definition of key.
+func (d *dictionary) lookup(key string) (entry, bool) {
+	if i, ok := d.latest[key]; ok {
+		return d.entries[i], true
+	}
+	return entry{}, false
+}
+
+// add appends a definition, hiding any older one with the same key.
+func (d *dictionary) add(e entry) {
+	if d.latest == nil {
+		d.latest = make(map[string]int)
+	}
+	d.latest[e.key] = len(d.entries)
+	d.entries = append(d.entries, e)
+}
+
+// forget removes the newest definition of key and every word defined
+// after it, returning the removed definition.
+func (d *dictionary) forget(key string) (entry, bool) {
+	i, ok := d.latest[key]
+	if !ok {
+		return entry{}, false
+	}
+	forgotten := d.entries[i]
+	d.entries = d.entries[:i]
+	d.latest = make(map[string]int)
+	for j, e := range d.entries {
+		d.latest[e.key] = j
+	}
+	return forgotten, true
+}
+
+// names lists each visible word once, newest first.
+func (d *dictionary) names() []string {
+	var names []string
+	for i := len(d.entries) - 1; i >= 0; i-- {
+		if d.latest[d.entries[i].key] == i {
+			names = append(names, d.entries[i].name)
+		}
+	}
+	return names
+}
+
+// define adds a user-defined word along with the source code it came from.
+// here is the size memory had before the word allocated any, which it
+// goes back to when the word is forgotten.
+func (in *Interpreter) define(name string, op operation, source string, here int) {
+	in.dict.add(entry{key: in.fold(name), name: name, op: op, source: source, here: here})
+}
+
+// Words returns the names of all user-defined words, newest first,
+// followed by the built-in words in alphabetical order.
+func (in *Interpreter) Words() []string {
+	return append(in.dict.names(), in.builtinNames()...)
+}
+
+// See returns the source code of a user-defined word, or a note that the
+// word is built in.
+func (in *Interpreter) See(name string) (string, error) {
+	key := in.fold(name)
+	if e, ok := in.dict.lookup(key); ok {
+		return e.source, nil
+	}
+	if in.isBuiltin(key) {
+		return name + " is built in", nil
+	}
+	return "", &UnknownWordError{Word: name}
+}
+
+// Forget removes a user-defined word and every word defined after it,
+// along with any memory they allocated. An older definition with the same
+// name becomes visible again.
+func (in *Interpreter) Forget(name string) error {
+	key := in.fold(name)
+	if e, ok := in.dict.forget(key); ok {
+		in.memory = in.memory[:e.here]
+		return nil
+	}
+	if in.isBuiltin(key) {
+		return fmt.Errorf("%s %w", name, ErrForgetBuiltin)
+	}
+	return &UnknownWordError{Word: name}
+}
+
+// seeWord parses `SEE name` and prints the word's source code.
+func (in *Interpreter) seeWord(index *int, lines []token) error {
+	name, err := parsedName(index, lines)
+	if err != nil {
+		return err
+	}
+	text, err := in.See(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(in.out, text+"\n")
+	return err
+}
+
+// forgetWord parses `FORGET name`.
+func (in *Interpreter) forgetWord(index *int, lines []token) error {
+	name, err := parsedName(index, lines)
+	if err != nil {
+		return err
+	}
+	return in.Forget(name)
+}
+
+// wordsOp prints every word the interpreter knows.
+func wordsOp(in *Interpreter) error {
+	_, err := io.WriteString(in.out, strings.Join(in.Words(), " ")+"\n")
+	return err
+}
+
+// parsingWords are handled by the interpreter itself, as they read the
+// words that follow them, rather than being looked up.
+var parsingWords = map[string]bool{
+	":": true, ";": true, "variable": true, "constant": true,
+	"see": true, "forget": true, "char": true, "include": true,
+}
+
+// isBuiltin reports whether key belongs to a built-in or registered word.
+func (in *Interpreter) isBuiltin(key string) bool {
+	if _, ok := in.hostWords[key]; ok {
+		return true
+	}
+	_, ok := builtinWords[key]
+	return ok || parsingWords[key] || controlWords[key] || stringWords[key]
+}
+
+// builtinNames lists every word the interpreter knows without being taught
+// in forth, including those registered from Go.
+func (in *Interpreter) builtinNames() []string {
+	var names []string
+	for _, words := range []map[string]bool{parsingWords, controlWords, stringWords} {
+		for name := range words {
+			names = append(names, name)
+		}
+	}
+	for name := range builtinWords {
+		names = append(names, name)
+	}
+	for key, e := range in.hostWords {
+		if _, ok := builtinWords[key]; !ok {
+			names = append(names, e.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,147 @@
+package forth
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	in := New()
+	for _, line := range []string{": b 1 ;", "variable a", "2 constant c"} {
+		if err := in.Eval(line); err != nil {
+			t.Fatalf("Eval(%q) returned an error: %q", line, err)
+		}
+	}
+	words := in.Words()
+	for i, expected := range []string{"c", "a", "b"} {
+		if words[i] != expected {
+			t.Fatalf("Words() expected %q at %d, got %v", expected, i, words)
+		}
+	}
+	found := false
+	for _, word := range words {
+		found = found || word == "dup"
+	}
+	if !found {
+		t.Fatalf("Words() expected to include built-in dup, got %v", words)
+	}
+}
+
+func TestSee(t *testing.T) {
+	in := New()
+	for _, line := range []string{`: greet ." hi  there" CR ;`, "variable x", "42 constant answer"} {
+		if err := in.Eval(line); err != nil {
+			t.Fatalf("Eval(%q) returned an error: %q", line, err)
+		}
+	}
+	for _, tc := range []struct {
+		name     string
+		expected string
+	}{
+		{"greet", `: greet ." hi  there" CR ;`},
+		{"GREET", `: greet ." hi  there" CR ;`},
+		{"x", "variable x"},
+		{"answer", "42 constant answer"},
+		{"dup", "dup is built in"},
+	} {
+		if text, err := in.See(tc.name); err != nil || text != tc.expected {
+			t.Fatalf("See(%q) expected %q, got %q, %v", tc.name, tc.expected, text, err)
+		}
+	}
+	var unknown *UnknownWordError
+	if _, err := in.See("nope"); !errors.As(err, &unknown) {
+		t.Fatalf("See(%q) expected an *UnknownWordError, got %v", "nope", err)
+	}
+}
+
+var dictionaryTestGroups = []testGroup{
+	{
+		group: "forget",
+		tests: []testCase{
+			{
+				"removes a word",
+				[]string{": foo 1 ;", "forget foo", "foo"},
+				[]int(nil),
+			},
+			{
+				"removes every word defined after it",
+				[]string{": foo 1 ;", ": bar 2 ;", "FORGET foo", "bar"},
+				[]int(nil),
+			},
+			{
+				"keeps words defined before it",
+				[]string{": foo 1 ;", ": bar 2 ;", "forget bar", "foo"},
+				[]int{1},
+			},
+			{
+				"brings back an older definition",
+				[]string{": foo 1 ;", ": foo 2 ;", "forget foo", "foo"},
+				[]int{1},
+			},
+			{
+				"brings back an overridden built-in",
+				[]string{": dup 5 ;", "forget dup", "1 dup"},
+				[]int{1, 1},
+			},
+			{
+				"errors on a built-in word",
+				[]string{"forget dup"},
+				[]int(nil),
+			},
+			{
+				"errors on an unknown word",
+				[]string{"forget foo"},
+				[]int(nil),
+			},
+			{
+				"errors without a name",
+				[]string{"forget"},
+				[]int(nil),
+			},
+			{
+				"errors inside a definition",
+				[]string{": foo forget bar ;"},
+				[]int(nil),
+			},
+		},
+	},
+}
+
+func TestDictionaryWords(t *testing.T) {
+	runTestGroups(t, dictionaryTestGroups)
+}
+
+func TestForgetReleasesMemory(t *testing.T) {
+	in := New(WithMemorySize(2))
+	for _, line := range []string{"variable a", "variable b", "forget b", "variable c", "7 c !", "a @ c @"} {
+		if err := in.Eval(line); err != nil {
+			t.Fatalf("Eval(%q) returned an error: %q", line, err)
+		}
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{0, 7}) {
+		t.Fatalf("expected %v, got %v", []int{0, 7}, v)
+	}
+	if err := in.Eval("forget a variable d variable e"); err != nil {
+		t.Fatalf("expected forget to release every later cell, got %v", err)
+	}
+}
+
+func TestWordsAndSeePrint(t *testing.T) {
+	var out bytes.Buffer
+	in := New(WithOutput(&out))
+	for _, line := range []string{": sq dup * ;", "see sq", "see +", "words"} {
+		if err := in.Eval(line); err != nil {
+			t.Fatalf("Eval(%q) returned an error: %q", line, err)
+		}
+	}
+	expected := ": sq dup * ;\n+ is built in\nsq "
+	if !strings.HasPrefix(out.String(), expected) {
+		t.Fatalf("expected output starting with %q, got %q", expected, out.String())
+	}
+	if err := in.Eval("see nope"); err == nil {
+		t.Fatalf("expected an error for SEE of an unknown word")
+	}
+}
@@ -0,0 +1,216 @@
+package forth
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// programHeader starts every encoded Program. The last byte is the version
+// of the encoding, to be bumped whenever the bytecode changes.
+const programHeader = "forth\x00bc\x05"
+
+// quietFlag is set in the opcode byte of a quiet instruction.
+const quietFlag = 0x80
+
+// MarshalBinary encodes the program so it can be saved and later loaded
+// with UnmarshalBinary, without compiling it again.
+func (p *Program) MarshalBinary() ([]byte, error) {
+	data := []byte(programHeader)
+	data = binary.AppendUvarint(data, uint64(len(p.tokens)))
+	for _, t := range p.tokens {
+		data = appendString(data, t.text)
+		data = binary.AppendUvarint(data, uint64(t.line))
+		data = binary.AppendUvarint(data, uint64(t.column))
+	}
+	data = binary.AppendUvarint(data, uint64(len(p.names)))
+	for _, name := range p.names {
+		data = appendString(data, name)
+	}
+	data = binary.AppendUvarint(data, uint64(len(p.bigs)))
+	for _, z := range p.bigs {
+		data = appendString(data, z.String())
+	}
+	data = binary.AppendUvarint(data, uint64(len(p.strings)))
+	for _, s := range p.strings {
+		data = appendString(data, s)
+	}
+	data = binary.AppendUvarint(data, uint64(len(p.defs)))
+	for _, def := range p.defs {
+		data = append(data, byte(def.kind))
+		data = appendString(data, def.name)
+		data = appendString(data, def.source)
+		data = appendCode(data, def.code)
+	}
+	return appendCode(data, p.main), nil
+}
+
+func appendString(data []byte, s string) []byte {
+	data = binary.AppendUvarint(data, uint64(len(s)))
+	return append(data, s...)
+}
+
+func appendCode(data []byte, code []instruction) []byte {
+	data = binary.AppendUvarint(data, uint64(len(code)))
+	for _, ins := range code {
+		b := byte(ins.op)
+		if ins.quiet {
+			b |= quietFlag
+		}
+		data = append(data, b)
+		data = binary.AppendVarint(data, int64(ins.arg))
+		data = binary.AppendUvarint(data, uint64(ins.pos))
+	}
+	return data
+}
+
+// UnmarshalBinary loads a program encoded by MarshalBinary. It fails with
+// ErrInvalidProgram if data isn't one, or was made by a different version
+// of the encoding.
+func (p *Program) UnmarshalBinary(data []byte) error {
+	if len(data) < len(programHeader) || string(data[:len(programHeader)]) != programHeader {
+		return fmt.Errorf("%w: unrecognized header", ErrInvalidProgram)
+	}
+	d := decoder{data: data[len(programHeader):]}
+	var q Program
+	q.tokens = make([]token, d.count())
+	for i := range q.tokens {
+		q.tokens[i] = token{text: d.string(), line: d.uint(), column: d.uint()}
+	}
+	q.names = make([]string, d.count())
+	for i := range q.names {
+		q.names[i] = d.string()
+	}
+	q.bigs = make([]*big.Int, d.count())
+	for i := range q.bigs {
+		z, ok := new(big.Int).SetString(d.string(), 10)
+		if !ok {
+			d.fail("bad number")
+		}
+		q.bigs[i] = z
+	}
+	q.strings = make([]string, d.count())
+	for i := range q.strings {
+		q.strings[i] = d.string()
+	}
+	q.defs = make([]definition, d.count())
+	for i := range q.defs {
+		kind := defKind(d.byte())
+		if kind >= defKindCount {
+			d.fail("bad definition kind")
+		}
+		q.defs[i] = definition{kind: kind, name: d.string(), source: d.string(), code: d.code(&q)}
+	}
+	q.main = d.code(&q)
+	if d.err == nil && len(d.data) > 0 {
+		d.fail("trailing data")
+	}
+	if d.err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidProgram, d.err)
+	}
+	*p = q
+	return nil
+}
+
+// decoder reads the parts of an encoded Program, remembering the first
+// problem it finds. After a problem it reads only zeros.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) fail(problem string) {
+	if d.err == nil {
+		d.err = errors.New(problem)
+	}
+	d.data = nil
+}
+
+func (d *decoder) uint() int {
+	n, size := binary.Uvarint(d.data)
+	if size <= 0 || n > math.MaxInt32 {
+		d.fail("bad number")
+		return 0
+	}
+	d.data = d.data[size:]
+	return int(n)
+}
+
+// count reads the length of a list, which can't be longer than the data
+// left to read it from.
+func (d *decoder) count() int {
+	n := d.uint()
+	if n > len(d.data) {
+		d.fail("bad length")
+		return 0
+	}
+	return n
+}
+
+func (d *decoder) byte() byte {
+	if len(d.data) < 1 {
+		d.fail("unexpected end of data")
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+	return b
+}
+
+func (d *decoder) string() string {
+	n := d.count()
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+// code reads a block of instructions, checking that each refers only to
+// parts of q that exist, so running it can't go out of bounds. An
+// unconditional branch can't jump to itself, as no compiled program does,
+// and a loop can only jump back.
+func (d *decoder) code(q *Program) []instruction {
+	code := make([]instruction, d.count())
+	for i := range code {
+		b := d.byte()
+		arg, size := binary.Varint(d.data)
+		if size <= 0 {
+			d.fail("bad number")
+		} else {
+			d.data = d.data[size:]
+		}
+		code[i] = instruction{op: opcode(b &^ quietFlag), arg: int(arg), pos: d.uint(), quiet: b&quietFlag != 0}
+	}
+	for i, ins := range code {
+		if ins.op >= opcodeCount || ins.pos >= len(q.tokens) {
+			d.fail("bad instruction")
+		}
+		limit := 0
+		switch ins.op {
+		case opPush, opDo, opBegin:
+			continue
+		case opPushBig:
+			limit = len(q.bigs)
+		case opCall, opSee, opForget, opInclude:
+			limit = len(q.names)
+		case opCallDef, opDefine:
+			limit = len(q.defs)
+		case opBranch:
+			if ins.arg == i {
+				d.fail("branch to itself")
+			}
+			limit = len(code) + 1
+		case opBranchIfFalse:
+			limit = len(code) + 1
+		case opLoop:
+			limit = i + 1
+		case opPrint, opString:
+			limit = len(q.strings)
+		}
+		if ins.arg < 0 || ins.arg >= limit {
+			d.fail("bad instruction")
+		}
+	}
+	return code
+}
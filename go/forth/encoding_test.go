@@ -0,0 +1,128 @@
+package forth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProgramRoundTrip(t *testing.T) {
+	for _, groups := range compiledTestGroups {
+		for _, tg := range groups {
+			for _, tc := range tg.tests {
+				p, err := Compile(tc.input)
+				if err != nil {
+					continue
+				}
+				data, err := p.MarshalBinary()
+				if err != nil {
+					t.Fatalf("FAIL: %s | %s\n\tMarshalBinary returned an error: %q", tg.group, tc.description, err)
+				}
+				var loaded Program
+				if err := loaded.UnmarshalBinary(data); err != nil {
+					t.Fatalf("FAIL: %s | %s\n\tUnmarshalBinary returned an error: %q", tg.group, tc.description, err)
+				}
+				if again, _ := loaded.MarshalBinary(); !bytes.Equal(again, data) {
+					t.Fatalf("FAIL: %s | %s\n\t%#v changed when saved again after loading", tg.group, tc.description, tc.input)
+				}
+				compiled, loadedIn := New(), New()
+				compiledErr, loadedErr := compiled.Run(p), loadedIn.Run(&loaded)
+				if (compiledErr == nil) != (loadedErr == nil) || !reflect.DeepEqual(compiled.Stack(), loadedIn.Stack()) {
+					t.Fatalf("FAIL: %s | %s\n\t%#v ran differently after loading: %v, %q and %v, %q",
+						tg.group, tc.description, tc.input, compiled.Stack(), compiledErr, loadedIn.Stack(), loadedErr)
+				}
+			}
+		}
+	}
+}
+
+func TestRunLoadedProgram(t *testing.T) {
+	p, err := Compile([]string{`: greet s" hello" type cr ;`, "greet 100000000000000000000 1 + ."})
+	if err != nil {
+		t.Fatalf("Compile returned an error: %q", err)
+	}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary returned an error: %q", err)
+	}
+	var loaded Program
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary returned an error: %q", err)
+	}
+	var out bytes.Buffer
+	if err := New(WithMode(BigMode), WithOutput(&out)).Run(&loaded); err != nil {
+		t.Fatalf("Run returned an error: %q", err)
+	}
+	if expected := "hello\n100000000000000000001 "; out.String() != expected {
+		t.Fatalf("expected output %q, got %q", expected, out.String())
+	}
+}
+
+func TestUnmarshalInvalidProgram(t *testing.T) {
+	p, err := Compile([]string{`: f 3 0 do i . loop ." done" ;`, "variable x f x @ 1000000000000000000000 drop"})
+	if err != nil {
+		t.Fatalf("Compile returned an error: %q", err)
+	}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary returned an error: %q", err)
+	}
+	for _, bad := range [][]byte{nil, []byte("1 2 +"), data[:len(data)-1], append(data[:len(data):len(data)], 0)} {
+		var loaded Program
+		if err := loaded.UnmarshalBinary(bad); !errors.Is(err, ErrInvalidProgram) {
+			t.Fatalf("UnmarshalBinary(%q) expected ErrInvalidProgram, got %v", bad, err)
+		}
+	}
+
+	// Corrupted programs must be rejected or run safely, never panic.
+	for i := len(programHeader); i < len(data); i++ {
+		for _, b := range []byte{0, 1, 0x7f, 0xff} {
+			corrupt := append([]byte(nil), data...)
+			corrupt[i] = b
+			var loaded Program
+			if loaded.UnmarshalBinary(corrupt) == nil {
+				New(WithMaxSteps(1000)).Run(&loaded)
+			}
+		}
+	}
+}
+
+func TestUnmarshalSelfBranch(t *testing.T) {
+	tokens := lex([]string{"begin again"})
+	for _, main := range [][]instruction{
+		{{op: opBranch, arg: 0}},
+		{{op: opBegin}, {op: opBranch, arg: 1, pos: 1}},
+		{{op: opLoop, arg: 1}},
+	} {
+		data, err := (&Program{tokens: tokens, main: main}).MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary returned an error: %q", err)
+		}
+		var loaded Program
+		if err := loaded.UnmarshalBinary(data); !errors.Is(err, ErrInvalidProgram) {
+			t.Fatalf("UnmarshalBinary(%v) expected ErrInvalidProgram, got %v", main, err)
+		}
+	}
+}
+
+func TestLoadedLoopsCanBeStopped(t *testing.T) {
+	p := &Program{tokens: lex([]string{"begin again"}), main: []instruction{{op: opBegin}, {op: opBranch, pos: 1}}}
+	data, err := p.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary returned an error: %q", err)
+	}
+	var loaded Program
+	if err := loaded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary returned an error: %q", err)
+	}
+	if err := New(WithMaxSteps(10)).Run(&loaded); !errors.Is(err, ErrStepLimit) {
+		t.Fatalf("expected ErrStepLimit, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := New().RunContext(ctx, &loaded); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package forth
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors returned while evaluating forth code. They are usually wrapped in an
+// *EvalError, so compare them with errors.Is.
+var (
+	// ErrStackUnderflow means a word needed more values than the stack held.
+	ErrStackUnderflow = errors.New("stack underflow")
+	// ErrStackOverflow means the stack is already at its maximum depth.
+	ErrStackOverflow = errors.New("stack overflow")
+	// ErrNegativeIndex means PICK or ROLL was given a negative index.
+	ErrNegativeIndex = errors.New("stack index can't be negative")
+	// ErrOverflow means a value didn't fit in an int, either because
+	// CheckedMode caught an arithmetic overflow or because a word that needs
+	// an int was given a larger value.
+	ErrOverflow = errors.New("integer overflow")
+	// ErrDivideByZero means the divisor on the stack was zero.
+	ErrDivideByZero = errors.New("can't divide by zero")
+	// ErrRedefineNumber means a colon definition tried to name a number.
+	ErrRedefineNumber = errors.New("numbers can't be redefined as user-defined words")
+	// ErrMissingName means a defining word was not followed by a name.
+	ErrMissingName = errors.New("definition is missing a name")
+	// ErrUnterminatedDefinition means a colon definition had no `;`.
+	ErrUnterminatedDefinition = errors.New("definition is missing its closing ;")
+	// ErrNestedDefinition means a colon definition started inside another.
+	ErrNestedDefinition = errors.New("word definitions can't be nested")
+	// ErrInterpretOnly means a defining word was used inside a word definition.
+	ErrInterpretOnly = errors.New("only valid outside a word definition")
+	// ErrForgetBuiltin means FORGET was given the name of a built-in word.
+	ErrForgetBuiltin = errors.New("is built in and can't be forgotten")
+	// ErrCompileOnly means a control word was used outside a word definition.
+	ErrCompileOnly = errors.New("only valid inside a word definition")
+	// ErrUnbalancedControl means a control structure is missing its opening
+	// or closing word.
+	ErrUnbalancedControl = errors.New("unbalanced control structure")
+	// ErrNotInLoop means a loop index was read outside of a running DO LOOP.
+	ErrNotInLoop = errors.New("loop index used outside of a do loop")
+	// ErrStepLimit means evaluation ran more steps than WithMaxSteps allows.
+	ErrStepLimit = errors.New("step limit exceeded")
+	// ErrCallDepthLimit means user-defined words were nested deeper than
+	// WithMaxCallDepth allows.
+	ErrCallDepthLimit = errors.New("call depth limit exceeded")
+	// ErrUnclosedString means a string literal had no closing quote.
+	ErrUnclosedString = errors.New("string literal is missing its closing quote")
+	// ErrReturnStackImbalance means a word left values on the return stack,
+	// or took more off it than it put on.
+	ErrReturnStackImbalance = errors.New("return stack is not balanced")
+	// ErrInvalidAddress means a memory address was outside allocated memory.
+	ErrInvalidAddress = errors.New("invalid memory address")
+	// ErrOutOfMemory means memory is already at its maximum size.
+	ErrOutOfMemory = errors.New("out of memory")
+	// ErrInvalidName means a word was given a name the interpreter can't
+	// look up: empty, containing whitespace, or one it parses itself.
+	ErrInvalidName = errors.New("invalid word name")
+	// ErrInvalidProgram means data given to Program.UnmarshalBinary wasn't
+	// a program encoded by MarshalBinary.
+	ErrInvalidProgram = errors.New("invalid compiled program")
+	// ErrMissingCharacter means CHAR or [CHAR] was not followed by a word.
+	ErrMissingCharacter = errors.New("is missing a character")
+	// ErrMissingWord means SEE, FORGET or INCLUDE was not followed by the
+	// name of a word or file.
+	ErrMissingWord = errors.New("is missing a name")
+	// ErrNoFileSystem means INCLUDE was used by an Interpreter without
+	// WithFS.
+	ErrNoFileSystem = errors.New("no file system to include files from")
+	// ErrRecursiveInclude means a file included itself, directly or through
+	// the files it included.
+	ErrRecursiveInclude = errors.New("includes itself")
+)
+
+// UnknownWordError reports a word that is neither built in nor user-defined.
+type UnknownWordError struct {
+	Word     string
+	Position int // index of the token where the word was found
+	Line     int
+	Column   int
+}
+
+func (e *UnknownWordError) Error() string {
+	return e.Word + " is not a built-in or recognized user-defined word"
+}
+
+// EvalError records the token where evaluation failed, both as an index
+// into the token stream and as a line and column in the source. File names
+// the included file the token was read from, if any.
+type EvalError struct {
+	File     string
+	Position int
+	Line     int
+	Column   int
+	Err      error
+}
+
+func (e *EvalError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s, line %d, column %d: %v", e.File, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *EvalError) Unwrap() error {
+	return e.Err
+}
+
+// underflow describes a stack underflow for a particular word.
+func underflow(message string) error {
+	return fmt.Errorf("%w: %s", ErrStackUnderflow, message)
+}
+
+// unbalanced describes a control structure error for a particular word.
+func unbalanced(message string) error {
+	return fmt.Errorf("%w: %s", ErrUnbalancedControl, message)
+}
+
+// positioned wraps err with the location of the token that caused it.
+// Errors found after the last token point at the last token. An error from
+// an included file keeps its own location, with the location of the
+// INCLUDE added around it.
+func positioned(err error, position int, tokens []token) error {
+	evalErr := &EvalError{Position: position, Err: err}
+	if len(tokens) > 0 {
+		at := tokens[len(tokens)-1]
+		if position < len(tokens) {
+			at = tokens[position]
+		}
+		evalErr.File, evalErr.Line, evalErr.Column = at.file, at.line, at.column
+	}
+	var included *EvalError
+	var unknown *UnknownWordError
+	if !errors.As(err, &included) && errors.As(err, &unknown) {
+		unknown.Position = position
+		unknown.Line, unknown.Column = evalErr.Line, evalErr.Column
+	}
+	return evalErr
+}
@@ -0,0 +1,63 @@
+package forth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestErrorsAreTyped(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       []string
+		target      error
+		position    int
+	}{
+		{"stack underflow", []string{"1 2 + +"}, ErrStackUnderflow, 3},
+		{"stack underflow in a user-defined word", []string{": f + ;", "f"}, ErrStackUnderflow, 4},
+		{"division by zero", []string{"4 0 /"}, ErrDivideByZero, 2},
+		{"redefining a number", []string{": 1 2 ;"}, ErrRedefineNumber, 1},
+		{"control word outside a definition", []string{"1 if"}, ErrCompileOnly, 1},
+		{"unbalanced control structure", []string{": f 1 then ;"}, ErrUnbalancedControl, 3},
+		{"loop index outside a loop", []string{": f i ;", "f"}, ErrNotInLoop, 4},
+	} {
+		_, err := Forth(tc.input)
+		if !errors.Is(err, tc.target) {
+			t.Fatalf("FAIL: %s\n\tForth(%#v) expected errors.Is(err, %q), got %v",
+				tc.description, tc.input, tc.target, err)
+		}
+		var evalErr *EvalError
+		if !errors.As(err, &evalErr) {
+			t.Fatalf("FAIL: %s\n\tForth(%#v) expected an *EvalError, got %T",
+				tc.description, tc.input, err)
+		}
+		if evalErr.Position != tc.position {
+			t.Fatalf("FAIL: %s\n\tForth(%#v) expected error at token %d, got %d",
+				tc.description, tc.input, tc.position, evalErr.Position)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
+
+func TestUnknownWordError(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       []string
+		word        string
+		position    int
+	}{
+		{"at the top level", []string{"1 2 foo"}, "foo", 2},
+		{"inside a definition", []string{": bar 1 foo ;"}, "foo", 3},
+	} {
+		_, err := Forth(tc.input)
+		var unknown *UnknownWordError
+		if !errors.As(err, &unknown) {
+			t.Fatalf("FAIL: %s\n\tForth(%#v) expected an *UnknownWordError, got %v",
+				tc.description, tc.input, err)
+		}
+		if unknown.Word != tc.word || unknown.Position != tc.position {
+			t.Fatalf("FAIL: %s\n\tForth(%#v) expected %q at token %d, got %q at token %d",
+				tc.description, tc.input, tc.word, tc.position, unknown.Word, unknown.Position)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
@@ -1,11 +1,12 @@
 package forth
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/big"
 	"strconv"
-	"strings"
-
-	"github.com/golang-collections/collections/stack"
 )
 
 const testVersion = 2
@@ -13,211 +14,558 @@ const testVersion = 2
 // Forth executes a simple subset of the forth language.
 // It returns a slice of integers.
 func Forth(codeText []string) ([]int, error) {
-	lines := lex(codeText)
-	stk, err := interpretLines(lines)
+	in := New()
+	err := in.evalContext(context.Background(), lex(codeText))
+	return in.Stack(), err
+}
+
+// Interpreter is a forth session whose data stack and user-defined words
+// persist across calls to Eval.
+type Interpreter struct {
+	stk        intStack
+	rstk       intStack
+	dict       dictionary
+	loops      []loopFrame
+	recurse    operation // the word being defined, for RECURSE
+	memory     []int
+	memorySize int
+	transient  []int // strings from S" outside definitions, see transientString
+	out        io.Writer
+	trace      func(TraceEvent)
+	hostWords  map[string]entry // registered by RegisterWord
+
+	caseSensitive bool
+
+	fsys      fs.FS    // where INCLUDE reads files from
+	including []string // the files being included, outermost first
 
-	results := make([]int, stk.Len())
-	// pop all values off the stack and return as slice
-	for i := stk.Len() - 1; i >= 0; i-- {
-		results[i] = stk.Pop().(int)
+	ctx          context.Context
+	steps        int
+	maxSteps     int
+	callDepth    int
+	maxCallDepth int
+}
+
+// operation is a compiled word, ready to run against an interpreter.
+type operation func(in *Interpreter) error
+
+// Option configures an Interpreter created by New.
+type Option func(*Interpreter)
+
+// WithMaxStackDepth limits how many values the data stack may hold.
+// Pushing past the limit fails with ErrStackOverflow.
+func WithMaxStackDepth(depth int) Option {
+	return func(in *Interpreter) {
+		in.stk.maxDepth = depth
 	}
-	return results, err
 }
 
-// lex turns an array of textual code lines into individual
-// tokens (numbers, operators, etc.).
-func lex(s []string) (lines []string) {
-	for _, line := range s {
-		tokens := strings.Split(line, " ")
-		for _, token := range tokens {
-			lines = append(lines, token)
-		}
+// WithMemorySize limits how many cells VARIABLE may allocate.
+// Allocating past the limit fails with ErrOutOfMemory.
+func WithMemorySize(cells int) Option {
+	return func(in *Interpreter) {
+		in.memorySize = cells
 	}
-	return lines
 }
 
-// interpretLines takes an array of tokens and executes them as instructions
-// (addition of numbers, assignment of variables, etc.).
-func interpretLines(lines []string) (*stack.Stack, error) {
-	stk := stack.New()
-	userDefinedWords := make(map[string][]string)
+// WithOutput sends everything printed by output words such as `.` to w.
+// Without it, output is discarded.
+func WithOutput(w io.Writer) Option {
+	return func(in *Interpreter) {
+		in.out = w
+	}
+}
+
+// New creates an Interpreter with an empty stack and dictionary.
+func New(options ...Option) *Interpreter {
+	in := new(Interpreter)
+	in.out = io.Discard
+	in.maxCallDepth = DefaultMaxCallDepth
+	in.stk.maxDepth = DefaultMaxStackDepth
+	in.rstk.maxDepth = DefaultMaxStackDepth
+	in.memorySize = DefaultMemorySize
+	for _, option := range options {
+		option(in)
+	}
+	in.Reset()
+	return in
+}
+
+// Eval executes a line of code against the current session.
+func (in *Interpreter) Eval(line string) error {
+	return in.EvalContext(context.Background(), line)
+}
+
+// EvalContext is like Eval, but stops with the context's error as soon as
+// ctx is done.
+func (in *Interpreter) EvalContext(ctx context.Context, line string) error {
+	return in.evalContext(ctx, lex([]string{line}))
+}
+
+// Stack returns a copy of the data stack, bottom value first.
+func (in *Interpreter) Stack() []int {
+	results := make([]int, in.stk.Len())
+	copy(results, in.stk.values)
+	return results
+}
+
+// Reset clears the data stack and memory, and forgets all user-defined words.
+func (in *Interpreter) Reset() {
+	in.stk.Clear()
+	in.rstk.Clear()
+	in.dict = dictionary{}
+	in.loops = nil
+	in.memory = nil
+	in.transient = nil
+}
 
+// eval takes an array of tokens and executes them as instructions
+// (addition of numbers, assignment of variables, etc.).
+// Errors record the index of the token where evaluation failed.
+func (in *Interpreter) eval(lines []token) error {
 	for i := 0; i < len(lines); i++ {
-		word := lines[i]
-		var err error
-		err = interpretWord(word, &i, lines, stk, userDefinedWords)
-		if err != nil {
-			return stk, err
+		if err := in.evalWord(&i, lines); err != nil {
+			return positioned(err, i, lines)
 		}
 	}
-	return stk, nil
+	return nil
+}
+
+// evalWord executes the token at *i. Defining words consume the tokens
+// that make up the definition and advance *i past them.
+func (in *Interpreter) evalWord(i *int, lines []token) error {
+	word := in.fold(lines[*i].text)
+	switch {
+	case word == ":":
+		return in.assignStmt(i, lines)
+	case word == "variable":
+		return in.defineVariable(i, lines)
+	case word == "constant":
+		return in.defineConstant(i, lines)
+	case word == "see":
+		return in.seeWord(i, lines)
+	case word == "include":
+		return in.includeWord(i, lines)
+	case word == "forget":
+		return in.forgetWord(i, lines)
+	case word == "char":
+		return in.pushChar(i, lines)
+	case controlWords[word]:
+		return fmt.Errorf("%s is %w", lines[*i].text, ErrCompileOnly)
+	}
+	op, err := in.interpretWord(lines[*i])
+	if err != nil {
+		return err
+	}
+	if err = in.step(); err != nil {
+		return err
+	}
+	return in.traced(op, lines, *i)(in)
+}
+
+// builtinWords maps the names of built-in keywords and operators to the
+// functions that implement them, which have the same shape as the words
+// Go callers add with RegisterWord.
+var builtinWords = map[string]operation{
+	"+":    stackOp(plusOp),
+	"-":    stackOp(minusOp),
+	"*":    stackOp(multiplyOp),
+	"/":    stackOp(divideOp),
+	"dup":  stackOp(dupOp),
+	"drop": stackOp(dropOp),
+	"swap": stackOp(swapOp),
+	"over": stackOp(overOp),
+
+	"mod":    stackOp(modOp),
+	"/mod":   stackOp(divModOp),
+	"negate": stackOp(negateOp),
+	"abs":    stackOp(absOp),
+	"min":    stackOp(minOp),
+	"max":    stackOp(maxOp),
+
+	"rot":   stackOp(rotOp),
+	"-rot":  stackOp(minusRotOp),
+	"nip":   stackOp(nipOp),
+	"tuck":  stackOp(tuckOp),
+	"pick":  stackOp(pickOp),
+	"roll":  stackOp(rollOp),
+	"2dup":  stackOp(twoDupOp),
+	"2drop": stackOp(twoDropOp),
+	"2swap": stackOp(twoSwapOp),
+	"depth": stackOp(depthOp),
+
+	"=":      stackOp(equalOp),
+	"<":      stackOp(lessThanOp),
+	">":      stackOp(greaterThanOp),
+	"0=":     stackOp(zeroEqualOp),
+	"and":    stackOp(andOp),
+	"or":     stackOp(orOp),
+	"invert": stackOp(invertOp),
+
+	"!":  storeOp,
+	"@":  fetchOp,
+	"+!": addStoreOp,
+
+	".":    printOp,
+	".s":   printStackOp,
+	"emit": emitOp,
+	"cr":   crOp,
+	"type": typeOp,
+}
+
+func init() {
+	// wordsOp lists builtinWords, so it can't appear in their initializer.
+	builtinWords["words"] = wordsOp
+}
+
+// stackOp adapts a word that only needs the data stack into an operation.
+func stackOp(fn func(*intStack) error) operation {
+	return func(in *Interpreter) error {
+		return fn(&in.stk)
+	}
 }
 
-// interpretWord reads each token and executes it appropriately.
-func interpretWord(word string, i *int, lines []string, stk *stack.Stack, userDefinedWords map[string][]string) error {
-	var num int
-	var err error
-	word = strings.ToLower(word)
-	if num, err = strconv.Atoi(word); err == nil {
+// compileWord resolves a token to the operation it currently means.
+// User-defined words take precedence over built-ins, so they can be overridden.
+func (in *Interpreter) compileWord(t token) (operation, error) {
+	word := in.fold(t.text)
+	if stringWords[word] {
+		return in.compileString(word, t)
+	}
+	if num, err := strconv.Atoi(word); err == nil {
 		// an int
-		stk.Push(num)
-	} else if statements, ok := userDefinedWords[word]; ok {
-		// user-defined words
-		for _, stmt := range statements {
-			interpretWord(stmt, i, lines, stk, userDefinedWords)
-		}
-	} else {
-		// built-in keywords and operators
-		switch word {
-		case "+":
-			if err = plusOp(stk); err != nil {
-				return err
-			}
-		case "-":
-			if err = minusOp(stk); err != nil {
-				return err
-			}
-		case "*":
-			if err = multiplyOp(stk); err != nil {
-				return err
-			}
-		case "/":
-			if err = divideOp(stk); err != nil {
-				return err
-			}
-		case "dup":
-			if err = dupOp(stk); err != nil {
-				return err
-			}
-		case "drop":
-			if err = dropOp(stk); err != nil {
-				return err
-			}
-		case "swap":
-			if err = swapOp(stk); err != nil {
-				return err
-			}
-		case "over":
-			if err = overOp(stk); err != nil {
-				return err
-			}
-		case ":":
-			if err = assignStmt(userDefinedWords, i, lines); err != nil {
-				return err
-			}
-		default:
-			return errors.New(word + " is not a built-in or recognized user-defined word")
-		}
+		return func(in *Interpreter) error {
+			return in.stk.Push(num)
+		}, nil
+	} else if num, ok := parseBig(word, err); ok {
+		// an int too large for Go, which only BigMode can hold
+		return func(in *Interpreter) error {
+			return in.stk.PushBig(num)
+		}, nil
 	}
-	return nil
+	if op, ok := in.lookup(word); ok {
+		return op, nil
+	}
+	return nil, &UnknownWordError{Word: t.text}
+}
+
+// lookup finds the operation for a word's key, preferring user-defined
+// words to registered ones and registered words to built-in ones.
+func (in *Interpreter) lookup(key string) (operation, bool) {
+	if e, ok := in.dict.lookup(key); ok {
+		return e.op, true
+	}
+	if e, ok := in.hostWords[key]; ok {
+		return e.op, true
+	}
+	op, ok := builtinWords[key]
+	return op, ok
 }
 
 // assignStmt parses user-defined words in the format `: var-name value ;`
-func assignStmt(userDefinedWords map[string][]string, index *int, lines []string) error {
-	stmtEndIndex := 0
-	for i := *index; i < len(lines); i++ {
-		if lines[i] == ";" {
-			stmtEndIndex = i
-			break
-		}
+// The body is compiled immediately, so each word keeps the meaning its
+// body had when it was defined even if those words are redefined later.
+func (in *Interpreter) assignStmt(index *int, lines []token) error {
+	start := *index
+	wordName, stmtEndIndex, err := colonDefinition(index, lines, in.caseSensitive)
+	if err != nil {
+		return err
 	}
-	wordName := lines[*index+1]
-	wordValues := lines[*index+2 : stmtEndIndex]
-	if _, err := strconv.Atoi(wordName); err == nil {
-		return errors.New("numbers can't be redefined as user-defined words")
+	// RECURSE compiles to a call through word, which is set once the body
+	// has been compiled.
+	var word operation
+	in.recurse = func(in *Interpreter) error {
+		return word(in)
 	}
-	userDefinedWords[wordName] = wordValues
+	defer func() {
+		in.recurse = nil
+	}()
+
+	// The body's S" strings are stored as it compiles, and belong to the
+	// word, so they are freed when it is forgotten or fails to compile.
+	here := len(in.memory)
+	pos := *index + 1
+	body, _, err := in.compileBody(lines[:stmtEndIndex], &pos)
+	if err != nil {
+		in.memory = in.memory[:here]
+		*index = pos
+		return err
+	}
+	word = func(in *Interpreter) error {
+		return in.callWord(func() error {
+			return runAll(in, body)
+		})
+	}
+	in.define(wordName, word, source(lines[start:stmtEndIndex+1]), here)
 	*index = stmtEndIndex
 	return nil
 }
 
-func plusOp(stk *stack.Stack) error {
-	if stk.Len() == 2 {
-		i2 := stk.Pop().(int)
-		i1 := stk.Pop().(int)
-		stk.Push(i1 + i2)
-	} else {
-		return errors.New("found a single '+', did you mean to prepend some numbers?")
+// colonDefinition reads the name of the colon definition that starts at
+// *index and finds the `;` that ends it, advancing *index to the name.
+// A string literal missing its closing quote swallows the `;`, so that is
+// reported instead, at the string.
+func colonDefinition(index *int, lines []token, caseSensitive bool) (string, int, error) {
+	name, err := definedName(index, lines, caseSensitive)
+	if err != nil {
+		return "", 0, err
 	}
-	return nil
+	for i := *index + 1; i < len(lines); i++ {
+		if lines[i].text == ";" {
+			return name, i, nil
+		}
+		if lines[i].unclosed {
+			*index = i
+			return "", 0, fmt.Errorf("%s %w", lines[i].text, ErrUnclosedString)
+		}
+	}
+	return "", 0, ErrUnterminatedDefinition
 }
 
-func minusOp(stk *stack.Stack) error {
-	if stk.Len() == 2 {
-		i2 := stk.Pop().(int)
-		i1 := stk.Pop().(int)
-		stk.Push(i1 - i2)
-	} else {
-		return errors.New("found a single '-', did you mean to prepend some numbers?")
+func plusOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		return stk.binaryOp(addInts, addBigs)
 	}
-	return nil
+	return underflow("found a single '+', did you mean to prepend some numbers?")
 }
 
-func multiplyOp(stk *stack.Stack) error {
-	if stk.Len() == 2 {
-		i2 := stk.Pop().(int)
-		i1 := stk.Pop().(int)
-		stk.Push(i1 * i2)
-	} else {
-		return errors.New("found a single '*', did you mean to prepend some numbers?")
+func minusOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		return stk.binaryOp(subtractInts, subtractBigs)
 	}
-	return nil
+	return underflow("found a single '-', did you mean to prepend some numbers?")
 }
 
-func divideOp(stk *stack.Stack) error {
-	if stk.Len() == 2 {
-		i2 := stk.Pop().(int)
-		i1 := stk.Pop().(int)
-		if i2 != 0 {
-			stk.Push(i1 / i2)
-		} else {
-			return errors.New("can't divide by zero")
+func multiplyOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		return stk.binaryOp(multiplyInts, multiplyBigs)
+	}
+	return underflow("found a single '*', did you mean to prepend some numbers?")
+}
+
+func divideOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		if stk.isZero(0) {
+			stk.Pop()
+			stk.Pop()
+			return ErrDivideByZero
 		}
-	} else {
-		return errors.New("found a single '/', did you mean to prepend some numbers?")
+		return stk.binaryOp(divideInts, divideBigs)
 	}
-	return nil
+	return underflow("found a single '/', did you mean to prepend some numbers?")
 }
 
-func dupOp(stk *stack.Stack) error {
-	if topValue := stk.Peek(); topValue != nil {
-		stk.Push(topValue)
-	} else {
-		return errors.New("can't dup without an argument")
+// modOp leaves the remainder of dividing the second value by the top one.
+// Like / it truncates toward zero, as Go does, rather than flooring toward
+// negative infinity, so the remainder takes the sign of the dividend:
+// -7 2 MOD is -1 where a floored Forth would give 1, and 7 -2 MOD is 1.
+func modOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		if stk.isZero(0) {
+			stk.Pop()
+			stk.Pop()
+			return ErrDivideByZero
+		}
+		return stk.binaryOp(remainderInts, remainderBigs)
 	}
-	return nil
+	return underflow("found a single 'mod', did you mean to prepend some numbers?")
+}
+
+// divModOp leaves both the remainder and, above it, the truncated quotient.
+func divModOp(stk *intStack) error {
+	if stk.Len() < 2 {
+		return underflow("found a single '/mod', did you mean to prepend some numbers?")
+	}
+	if stk.isZero(0) {
+		stk.Pop()
+		stk.Pop()
+		return ErrDivideByZero
+	}
+	if !stk.isBig(0) && !stk.isBig(1) {
+		b := stk.Pop()
+		a := stk.Pop()
+		quotient, overflow := divideInts(a, b)
+		if !overflow || stk.mode == WrapMode {
+			return stk.Push(a%b, quotient)
+		}
+		if stk.mode == CheckedMode {
+			return ErrOverflow
+		}
+		if err := stk.Push(a, b); err != nil {
+			return err
+		}
+	}
+	b := stk.PopBig()
+	a := stk.PopBig()
+	quotient, remainder := new(big.Int).QuoRem(a, b, new(big.Int))
+	if err := stk.PushBig(remainder); err != nil {
+		return err
+	}
+	return stk.PushBig(quotient)
+}
+
+func negateOp(stk *intStack) error {
+	if stk.Len() >= 1 {
+		return stk.unaryOp(negateInt, negateBig)
+	}
+	return underflow("can't negate without an argument")
+}
+
+func absOp(stk *intStack) error {
+	if stk.Len() >= 1 {
+		return stk.unaryOp(absInt, absBig)
+	}
+	return underflow("can't take abs without an argument")
+}
+
+func minOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		if stk.compareTop() > 0 {
+			stk.Roll(1)
+		}
+		stk.Pop()
+		return nil
+	}
+	return underflow("found a single 'min', did you mean to prepend some numbers?")
+}
+
+func maxOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		if stk.compareTop() < 0 {
+			stk.Roll(1)
+		}
+		stk.Pop()
+		return nil
+	}
+	return underflow("found a single 'max', did you mean to prepend some numbers?")
 }
 
-func dropOp(stk *stack.Stack) error {
+func dupOp(stk *intStack) error {
+	if stk.Len() > 0 {
+		return stk.Copy(0)
+	}
+	return underflow("can't dup without an argument")
+}
+
+func dropOp(stk *intStack) error {
 	if stk.Len() > 0 {
 		stk.Pop()
-	} else {
-		return errors.New("can't drop if there is no argument")
+		return nil
 	}
-	return nil
+	return underflow("can't drop if there is no argument")
 }
 
-func swapOp(stk *stack.Stack) error {
+func swapOp(stk *intStack) error {
 	if stk.Len() >= 2 {
-		top := stk.Pop()
-		next := stk.Pop()
-		stk.Push(top)
-		stk.Push(next)
-	} else if stk.Len() < 2 {
-		return errors.New("can't swap unless there are at least two values")
+		stk.Roll(1)
+		return nil
 	}
-	return nil
+	return underflow("can't swap unless there are at least two values")
 }
 
-func overOp(stk *stack.Stack) error {
+func overOp(stk *intStack) error {
 	if stk.Len() >= 2 {
-		top := stk.Pop()
-		next := stk.Pop()
-		stk.Push(next)
-		stk.Push(top)
-		stk.Push(next)
-	} else {
-		return errors.New("can't copy with over if there are no arguments")
+		return stk.Copy(1)
 	}
-	return nil
+	return underflow("can't copy with over if there are no arguments")
+}
+
+func rotOp(stk *intStack) error {
+	if stk.Len() >= 3 {
+		stk.Roll(2)
+		return nil
+	}
+	return underflow("can't rotate with rot unless there are at least three values")
+}
+
+func minusRotOp(stk *intStack) error {
+	if stk.Len() >= 3 {
+		stk.Roll(2)
+		stk.Roll(2)
+		return nil
+	}
+	return underflow("can't rotate with -rot unless there are at least three values")
+}
+
+func nipOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		stk.Roll(1)
+		stk.Pop()
+		return nil
+	}
+	return underflow("can't nip unless there are at least two values")
+}
+
+func tuckOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		if err := stk.Copy(0); err != nil {
+			return err
+		}
+		return minusRotOp(stk)
+	}
+	return underflow("can't tuck unless there are at least two values")
+}
+
+func pickOp(stk *intStack) error {
+	if stk.Len() < 1 {
+		return underflow("can't pick without an index")
+	}
+	n, err := stk.PopInt()
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return ErrNegativeIndex
+	}
+	if stk.Len() > n {
+		return stk.Copy(n)
+	}
+	return underflow("can't pick deeper than the stack")
+}
+
+func rollOp(stk *intStack) error {
+	if stk.Len() < 1 {
+		return underflow("can't roll without an index")
+	}
+	n, err := stk.PopInt()
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return ErrNegativeIndex
+	}
+	if stk.Len() > n {
+		stk.Roll(n)
+		return nil
+	}
+	return underflow("can't roll deeper than the stack")
+}
+
+func twoDupOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		return stk.Copy(1, 0)
+	}
+	return underflow("can't 2dup unless there are at least two values")
+}
+
+func twoDropOp(stk *intStack) error {
+	if stk.Len() >= 2 {
+		stk.Pop()
+		stk.Pop()
+		return nil
+	}
+	return underflow("can't 2drop unless there are at least two values")
+}
+
+func twoSwapOp(stk *intStack) error {
+	if stk.Len() >= 4 {
+		stk.Roll(3)
+		stk.Roll(3)
+		return nil
+	}
+	return underflow("can't 2swap unless there are at least four values")
+}
+
+func depthOp(stk *intStack) error {
+	return stk.Push(stk.Len())
 }
@@ -2,9 +2,14 @@ package forth
 
 // API:
 // func Forth([]string) ([]int, error)
+// func New(...Option) *Interpreter
+// func (*Interpreter) Eval(string) error
+// func (*Interpreter) Stack() []int
+// func (*Interpreter) Reset()
 //
 
 import (
+	"context"
 	"reflect"
 	"testing"
 )
@@ -18,26 +23,71 @@ func TestTestVersion(t *testing.T) {
 }
 
 func TestForth(t *testing.T) {
-	for _, tg := range testGroups {
-		for _, tc := range tg.tests {
-			if v, err := Forth(tc.input); err == nil {
-				var _ error = err
-				if tc.expected == nil {
-					t.Fatalf("FAIL: %s | %s\n\tForth(%#v) expected an error, got %v",
-						tg.group, tc.description, tc.input, v)
-				} else if !reflect.DeepEqual(v, tc.expected) {
-					t.Fatalf("FAIL: %s | %s\n\tForth(%#v) expected %v, got %v",
-						tg.group, tc.description, tc.input, tc.expected, v)
+	runTestGroups(t, testGroups)
+}
+
+// modes lists the integer modes every test group runs under.
+var modes = []Mode{WrapMode, CheckedMode, BigMode}
+
+// runTestGroups evaluates each test case in every mode and checks the
+// resulting stack, or that an error was returned when none is expected.
+func runTestGroups(t *testing.T, groups []testGroup) {
+	for _, mode := range modes {
+		for _, tg := range groups {
+			for _, tc := range tg.tests {
+				if v, err := forthInMode(mode, tc.input); err == nil {
+					var _ error = err
+					if tc.expected == nil {
+						t.Fatalf("FAIL: %s | %s | %s mode\n\tForth(%#v) expected an error, got %v",
+							tg.group, tc.description, mode, tc.input, v)
+					} else if !reflect.DeepEqual(v, tc.expected) {
+						t.Fatalf("FAIL: %s | %s | %s mode\n\tForth(%#v) expected %v, got %v",
+							tg.group, tc.description, mode, tc.input, tc.expected, v)
+					}
+				} else if tc.expected != nil {
+					t.Fatalf("FAIL: %s | %s | %s mode\n\tForth(%#v) expected %v, got an error: %q",
+						tg.group, tc.description, mode, tc.input, tc.expected, err)
 				}
-			} else if tc.expected != nil {
-				t.Fatalf("FAIL: %s | %s\n\tForth(%#v) expected %v, got an error: %q",
-					tg.group, tc.description, tc.input, tc.expected, err)
+				t.Logf("PASS: %s | %s | %s mode", tg.group, tc.description, mode)
 			}
-			t.Logf("PASS: %s | %s", tg.group, tc.description)
 		}
 	}
 }
 
+// forthInMode is Forth, run in the given integer mode.
+func forthInMode(mode Mode, codeText []string) ([]int, error) {
+	if mode == WrapMode {
+		return Forth(codeText)
+	}
+	in := New(WithMode(mode))
+	err := in.evalContext(context.Background(), lex(codeText))
+	return in.Stack(), err
+}
+
+func TestInterpreterKeepsSession(t *testing.T) {
+	in := New()
+	for _, line := range []string{": dup-twice dup dup ;", "1", "dup-twice"} {
+		if err := in.Eval(line); err != nil {
+			t.Fatalf("Eval(%q) returned an error: %q", line, err)
+		}
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{1, 1, 1}) {
+		t.Fatalf("Stack() expected %v, got %v", []int{1, 1, 1}, v)
+	}
+	// Reading the stack must not consume it.
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{1, 1, 1}) {
+		t.Fatalf("second Stack() expected %v, got %v", []int{1, 1, 1}, v)
+	}
+
+	in.Reset()
+	if v := in.Stack(); len(v) != 0 {
+		t.Fatalf("Stack() after Reset() expected an empty stack, got %v", v)
+	}
+	if err := in.Eval("1 dup-twice"); err == nil {
+		t.Fatalf("Eval(%q) after Reset() expected an error for a forgotten word", "1 dup-twice")
+	}
+}
+
 func BenchmarkForth(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		for _, tg := range testGroups {
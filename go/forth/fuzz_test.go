@@ -0,0 +1,135 @@
+package forth
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// addSeeds adds the input of every table test, starting with those in
+// cases_test.go, to the fuzzer's seed corpus.
+func addSeeds(f *testing.F) {
+	for _, groups := range compiledTestGroups {
+		for _, tg := range groups {
+			for _, tc := range tg.tests {
+				f.Add(strings.Join(tc.input, "\n"))
+			}
+		}
+	}
+}
+
+// fuzzForth is Forth with limits, so that fuzzed programs that loop
+// forever or grow without bound fail quickly instead.
+func fuzzForth(code string) ([]int, error) {
+	in := New(WithMaxSteps(10000), WithMaxStackDepth(1024), WithMemorySize(1024), WithMaxCallDepth(64))
+	err := in.evalContext(context.Background(), lex([]string{code}))
+	return in.Stack(), err
+}
+
+// fuzzRun is fuzzForth, compiled to bytecode and run.
+func fuzzRun(code string) ([]int, error) {
+	p, err := Compile([]string{code})
+	if err != nil {
+		return nil, err
+	}
+	in := New(WithMaxSteps(10000), WithMaxStackDepth(1024), WithMemorySize(1024), WithMaxCallDepth(64))
+	err = in.Run(p)
+	return in.Stack(), err
+}
+
+// typedErrors are the errors evaluation may fail with, besides an
+// *UnknownWordError.
+var typedErrors = []error{
+	ErrStackUnderflow, ErrStackOverflow, ErrNegativeIndex, ErrOverflow,
+	ErrDivideByZero, ErrRedefineNumber, ErrMissingName, ErrUnterminatedDefinition,
+	ErrNestedDefinition, ErrInterpretOnly, ErrForgetBuiltin, ErrCompileOnly,
+	ErrUnbalancedControl, ErrNotInLoop, ErrStepLimit, ErrCallDepthLimit,
+	ErrUnclosedString, ErrReturnStackImbalance, ErrInvalidAddress, ErrOutOfMemory,
+	ErrInvalidName, ErrMissingCharacter, ErrNoFileSystem, ErrMissingWord,
+}
+
+// checkTyped fails unless err is an *EvalError wrapping one of the
+// typedErrors or an *UnknownWordError.
+func checkTyped(t *testing.T, code string, err error) {
+	var evalErr *EvalError
+	if !errors.As(err, &evalErr) {
+		t.Fatalf("%q returned an error without a position: %v", code, err)
+	}
+	var unknown *UnknownWordError
+	if errors.As(err, &unknown) {
+		return
+	}
+	for _, target := range typedErrors {
+		if errors.Is(err, target) {
+			return
+		}
+	}
+	t.Fatalf("%q returned an untyped error: %v", code, err)
+}
+
+func FuzzForth(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, code string) {
+		stack, err := fuzzForth(code)
+		if err != nil {
+			checkTyped(t, code, err)
+		}
+
+		again, againErr := fuzzForth(code)
+		if !reflect.DeepEqual(stack, again) || (err == nil) != (againErr == nil) ||
+			(err != nil && err.Error() != againErr.Error()) {
+			t.Fatalf("%q is not deterministic: %v, %v then %v, %v", code, stack, err, again, againErr)
+		}
+
+		compiled, compiledErr := fuzzRun(code)
+		if compiledErr != nil {
+			checkTyped(t, code, compiledErr)
+		}
+		if errors.Is(err, ErrStepLimit) || errors.Is(compiledErr, ErrStepLimit) {
+			// The two count steps a little differently.
+			return
+		}
+		if err == nil && (compiledErr != nil || !reflect.DeepEqual(stack, compiled)) {
+			t.Fatalf("%q evaluated to %v, but compiled to %v, %v", code, stack, compiled, compiledErr)
+		}
+	})
+}
+
+func FuzzLex(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, code string) {
+		tokens := lex([]string{code})
+		if again := lex([]string{code}); !reflect.DeepEqual(tokens, again) {
+			t.Fatalf("lex(%q) is not deterministic: %v then %v", code, tokens, again)
+		}
+
+		var lines [][]rune
+		for _, line := range strings.Split(code, "\n") {
+			lines = append(lines, []rune(line))
+		}
+		last := token{line: 1}
+		for _, tok := range tokens {
+			if tok.text == "" || strings.IndexFunc(tok.text, unicode.IsSpace) >= 0 {
+				t.Fatalf("lex(%q) returned a token that isn't a word: %#v", code, tok)
+			}
+			if tok.text == "(" || tok.text == "\\" {
+				t.Fatalf("lex(%q) returned a comment as a token: %#v", code, tok)
+			}
+			if tok.line < last.line || tok.line == last.line && tok.column <= last.column || tok.line > len(lines) {
+				t.Fatalf("lex(%q) returned a token out of order: %#v after %#v", code, tok, last)
+			}
+			line, text := lines[tok.line-1], []rune(tok.text)
+			start, end := tok.column-1, tok.column-1+len(text)
+			if start < 0 || end > len(line) || string(line[start:end]) != tok.text {
+				t.Fatalf("lex(%q) returned a token that isn't where it says: %#v", code, tok)
+			}
+			if tok.quoted != "" && !stringWords[strings.ToLower(tok.text)] {
+				t.Fatalf("lex(%q) returned a string literal for a word that doesn't take one: %#v", code, tok)
+			}
+			last = tok
+		}
+	})
+}
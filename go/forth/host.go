@@ -0,0 +1,116 @@
+package forth
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// RegisterWord makes fn available as the word name, so Go code can extend
+// the language without changing the interpreter. fn takes its arguments
+// from the stack and leaves its results there, using helpers such as Pop
+// and Push.
+//
+// Registered words are found after user-defined words and before built-in
+// ones, so they may replace a built-in word and a colon definition may in
+// turn shadow them. Like built-in words they survive Reset and can't be
+// forgotten. Words the interpreter parses itself, such as `:` and IF, can't
+// be registered.
+func (in *Interpreter) RegisterWord(name string, fn func(*Interpreter) error) error {
+	key := in.fold(name)
+	if key == "" || strings.IndexFunc(key, unicode.IsSpace) >= 0 ||
+		parsingWords[key] || controlWords[key] || stringWords[key] {
+		return fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	if _, err := strconv.Atoi(key); err == nil {
+		return ErrRedefineNumber
+	}
+	if in.hostWords == nil {
+		in.hostWords = make(map[string]entry)
+	}
+	in.hostWords[key] = entry{key: key, name: name, op: fn}
+	return nil
+}
+
+// Pop removes the top value from the stack.
+func (in *Interpreter) Pop() (int, error) {
+	if in.stk.Len() < 1 {
+		return 0, underflow("expected a value on the stack")
+	}
+	return in.stk.PopInt()
+}
+
+// Push adds values to the top of the stack, the last one ending up on top.
+func (in *Interpreter) Push(values ...int) error {
+	return in.stk.Push(values...)
+}
+
+// PopBool removes the top value from the stack as a flag. Any non-zero
+// value is true.
+func (in *Interpreter) PopBool() (bool, error) {
+	if in.stk.Len() < 1 {
+		return false, underflow("expected a flag on the stack")
+	}
+	zero := in.stk.isZero(0)
+	in.stk.Pop()
+	return !zero, nil
+}
+
+// PushBool adds a flag to the top of the stack: -1 for true, 0 for false.
+func (in *Interpreter) PushBool(b bool) error {
+	return in.stk.Push(flag(b))
+}
+
+// PopBig removes the top value from the stack exactly, even if it outgrew
+// an int in BigMode.
+func (in *Interpreter) PopBig() (*big.Int, error) {
+	if in.stk.Len() < 1 {
+		return nil, underflow("expected a value on the stack")
+	}
+	return in.stk.PopBig(), nil
+}
+
+// PushBig adds z to the top of the stack. Only BigMode can hold values
+// that don't fit in an int; other modes fail with ErrOverflow.
+func (in *Interpreter) PushBig(z *big.Int) error {
+	return in.stk.PushBig(new(big.Int).Set(z))
+}
+
+// PopString removes an address and, above it, a length from the stack and
+// returns the string stored in memory there, one character per cell, as
+// S" leaves it.
+func (in *Interpreter) PopString() (string, error) {
+	if in.stk.Len() < 2 {
+		return "", underflow("expected an address and a length on the stack")
+	}
+	length, err := in.stk.PopInt()
+	if err != nil {
+		return "", err
+	}
+	address, err := in.stk.PopInt()
+	if err != nil {
+		return "", err
+	}
+	var text []rune
+	for i := 0; i < length; i++ {
+		cell, err := in.cell(address + i)
+		if err != nil {
+			return "", err
+		}
+		text = append(text, rune(*cell))
+	}
+	return string(text), nil
+}
+
+// PushString stores s in memory, one character per cell, and adds its
+// address and length to the stack, as S" in a definition does. The string
+// stays in memory until a word defined before it is forgotten.
+func (in *Interpreter) PushString(s string) error {
+	address, length, err := in.storeString(s)
+	if err != nil {
+		return err
+	}
+	return in.stk.Push(address, length)
+}
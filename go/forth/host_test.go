@@ -0,0 +1,151 @@
+package forth
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+func TestRegisterWord(t *testing.T) {
+	reading := 21
+	in := New()
+	if err := in.RegisterWord("Sensor", func(in *Interpreter) error {
+		return in.Push(reading)
+	}); err != nil {
+		t.Fatalf("RegisterWord returned an error: %q", err)
+	}
+	if err := in.Eval(": twice sensor 2 * ; SENSOR twice"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{21, 42}) {
+		t.Fatalf("expected [21 42], got %v", v)
+	}
+
+	in.Reset()
+	reading = 5
+	if err := in.Eval("sensor"); err != nil {
+		t.Fatalf("Eval after Reset returned an error: %q", err)
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{5}) {
+		t.Fatalf("expected [5] after Reset, got %v", v)
+	}
+}
+
+func TestRegisterWordPrecedence(t *testing.T) {
+	in := New()
+	if err := in.RegisterWord("+", func(in *Interpreter) error {
+		b, err := in.Pop()
+		if err != nil {
+			return err
+		}
+		a, err := in.Pop()
+		if err != nil {
+			return err
+		}
+		return in.Push(a*10 + b)
+	}); err != nil {
+		t.Fatalf("RegisterWord returned an error: %q", err)
+	}
+	if err := in.Eval("1 2 + : + - ; 5 3 +"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{12, 2}) {
+		t.Fatalf("expected [12 2], got %v", v)
+	}
+	if err := in.Eval("forget + forget +"); !errors.Is(err, ErrForgetBuiltin) {
+		t.Fatalf("expected ErrForgetBuiltin, got %v", err)
+	}
+}
+
+func TestRegisterWordErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		target error
+	}{
+		{"", ErrInvalidName},
+		{"two words", ErrInvalidName},
+		{":", ErrInvalidName},
+		{"IF", ErrInvalidName},
+		{`."`, ErrInvalidName},
+		{"variable", ErrInvalidName},
+		{"42", ErrRedefineNumber},
+	} {
+		err := New().RegisterWord(tc.name, func(*Interpreter) error { return nil })
+		if !errors.Is(err, tc.target) {
+			t.Fatalf("RegisterWord(%q) expected errors.Is(err, %q), got %v", tc.name, tc.target, err)
+		}
+	}
+}
+
+func TestRegisteredWordsAreListed(t *testing.T) {
+	var out bytes.Buffer
+	in := New(WithOutput(&out))
+	in.RegisterWord("sensor", func(*Interpreter) error { return nil })
+	if err := in.Eval("see sensor"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	if out.String() != "sensor is built in\n" {
+		t.Fatalf("expected SEE to call sensor built in, got %q", out.String())
+	}
+	found := false
+	for _, name := range in.Words() {
+		found = found || name == "sensor"
+	}
+	if !found {
+		t.Fatalf("expected Words to list sensor, got %v", in.Words())
+	}
+}
+
+func TestTypedHelpers(t *testing.T) {
+	var got []interface{}
+	in := New(WithMode(BigMode))
+	in.RegisterWord("inspect", func(in *Interpreter) error {
+		s, err := in.PopString()
+		if err != nil {
+			return err
+		}
+		b, err := in.PopBool()
+		if err != nil {
+			return err
+		}
+		z, err := in.PopBig()
+		if err != nil {
+			return err
+		}
+		got = append(got, s, b, z.String())
+		if err := in.PushBool(b); err != nil {
+			return err
+		}
+		if err := in.PushBig(new(big.Int).Neg(z)); err != nil {
+			return err
+		}
+		return in.PushString(s + "!")
+	})
+	if err := in.Eval(`100000000000000000000 7 s" hi" inspect type`); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	if expected := []interface{}{"hi", true, "100000000000000000000"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected helpers to pop %v, got %v", expected, got)
+	}
+	if v := in.BigStack(); len(v) != 2 || v[0].Int64() != -1 || v[1].String() != "-100000000000000000000" {
+		t.Fatalf("expected helpers to push -1 and -100000000000000000000, got %v", v)
+	}
+}
+
+func TestHelpersReportUnderflow(t *testing.T) {
+	in := New()
+	if _, err := in.Pop(); !errors.Is(err, ErrStackUnderflow) {
+		t.Fatalf("Pop expected ErrStackUnderflow, got %v", err)
+	}
+	if _, err := in.PopBool(); !errors.Is(err, ErrStackUnderflow) {
+		t.Fatalf("PopBool expected ErrStackUnderflow, got %v", err)
+	}
+	if _, err := in.PopBig(); !errors.Is(err, ErrStackUnderflow) {
+		t.Fatalf("PopBig expected ErrStackUnderflow, got %v", err)
+	}
+	if _, err := in.PopString(); !errors.Is(err, ErrStackUnderflow) {
+		t.Fatalf("PopString expected ErrStackUnderflow, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package forth
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// WithFS lets INCLUDE and EvalFile read files from fsys, and from nowhere
+// else. Paths are slash-separated, as for fs.FS, and can't climb out of
+// fsys with `..`. Whether a symbolic link can lead out of it is up to fsys:
+// os.DirFS follows links wherever they go, so to sandbox scripts to a
+// directory use the FS of an os.Root, from os.OpenRoot, which doesn't.
+// Without it, INCLUDE fails with ErrNoFileSystem.
+func WithFS(fsys fs.FS) Option {
+	return func(in *Interpreter) {
+		in.fsys = fsys
+	}
+}
+
+// EvalReader executes the code read from r against the current session,
+// just as Eval would execute all of it at once. Each line runs as soon as
+// it has been read, unless it leaves a definition or a `( comment` open, or
+// ends with a word such as VARIABLE whose name is on the next line. Then
+// it runs along with the lines that complete it. Errors record lines and
+// columns counted from the start of r, and positions counted from the
+// start of the line that ran, or of the first of the lines that ran
+// together.
+func (in *Interpreter) EvalReader(r io.Reader) error {
+	return in.EvalReaderContext(context.Background(), r)
+}
+
+// EvalReaderContext is like EvalReader, but stops with the context's error
+// as soon as ctx is done, once it is no longer waiting to read from r.
+func (in *Interpreter) EvalReaderContext(ctx context.Context, r io.Reader) error {
+	defer in.watch(ctx)()
+	br := bufio.NewReader(r)
+	var tokens []token
+	line, inComment := 0, false
+	for {
+		text, err := br.ReadString('\n')
+		if text != "" {
+			line++
+			tokens, inComment = lexLine(tokens, []rune(strings.TrimSuffix(text, "\n")), line, inComment)
+			if in.complete(tokens) {
+				if err := in.eval(tokens); err != nil {
+					return err
+				}
+				// Definitions keep the tokens they were read from, so
+				// the next ones need a fresh slice.
+				tokens = nil
+			}
+		}
+		if err == io.EOF {
+			return in.eval(tokens)
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// complete reports whether tokens can be evaluated without the code that
+// follows them: no colon definition is waiting for its `;`, and no word
+// such as VARIABLE for the name that follows it.
+func (in *Interpreter) complete(tokens []token) bool {
+	for i := 0; i < len(tokens); i++ {
+		word := in.fold(tokens[i].text)
+		switch {
+		case word == ":":
+			i++
+			for i < len(tokens) && tokens[i].text != ";" {
+				i++
+			}
+			if i == len(tokens) {
+				return false
+			}
+		case parsingWords[word] && word != ";":
+			if i++; i == len(tokens) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// EvalFile executes the named file from the interpreter's file system, as
+// `INCLUDE name` would. Errors in the file record its name, and INCLUDE
+// inside it finds other files relative to it.
+func (in *Interpreter) EvalFile(name string) error {
+	return in.EvalFileContext(context.Background(), name)
+}
+
+// EvalFileContext is like EvalFile, but stops with the context's error as
+// soon as ctx is done.
+func (in *Interpreter) EvalFileContext(ctx context.Context, name string) error {
+	defer in.watch(ctx)()
+	return in.include("", name)
+}
+
+// includeWord parses `INCLUDE path` and executes the file.
+func (in *Interpreter) includeWord(index *int, lines []token) error {
+	from := lines[*index].file
+	name, err := parsedName(index, lines)
+	if err != nil {
+		return err
+	}
+	return in.include(from, name)
+}
+
+// include executes the file name, read from the file system. A relative
+// name is found in the directory of the file from, and a name starting
+// with / at the root of the file system.
+func (in *Interpreter) include(from, name string) error {
+	if in.fsys == nil {
+		return fmt.Errorf("%s: %w", name, ErrNoFileSystem)
+	}
+	file := resolve(from, name)
+	if !fs.ValidPath(file) {
+		return &fs.PathError{Op: "include", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, including := range in.including {
+		if including == file {
+			return fmt.Errorf("%s %w", file, ErrRecursiveInclude)
+		}
+	}
+	code, err := fs.ReadFile(in.fsys, file)
+	if err != nil {
+		return err
+	}
+
+	in.including = append(in.including, file)
+	defer func() {
+		in.including = in.including[:len(in.including)-1]
+	}()
+	tokens := lex([]string{string(code)})
+	for i := range tokens {
+		tokens[i].file = file
+	}
+	return in.eval(tokens)
+}
+
+// resolve returns the path in the file system of the file name, included
+// from the file from.
+func resolve(from, name string) string {
+	if path.IsAbs(name) {
+		return path.Clean(name[1:])
+	}
+	return path.Join(path.Dir(from), name)
+}
@@ -0,0 +1,179 @@
+package forth
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+var includeFS = fstest.MapFS{
+	"main.fs":         {Data: []byte("include lib/math.fs\n3 square\n")},
+	"lib/math.fs":     {Data: []byte("include util.fs\n: square ( n -- n*n )\n  dup * ;\n")},
+	"lib/util.fs":     {Data: []byte(": twice 2 * ;\n")},
+	"lib/rooted.fs":   {Data: []byte("include /lib/util.fs 4 twice\n")},
+	"lib/escape.fs":   {Data: []byte("include ../../secret.fs\n")},
+	"loop/a.fs":       {Data: []byte("1 include b.fs\n")},
+	"loop/b.fs":       {Data: []byte("2 include a.fs\n")},
+	"broken/main.fs":  {Data: []byte("1\ninclude bad.fs\n")},
+	"broken/bad.fs":   {Data: []byte("2\n  3 bogus\n")},
+	"broken/short.fs": {Data: []byte("1 +\n")},
+}
+
+func TestInclude(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       string
+		expected    []int // nil slice indicates error expected.
+	}{
+		{"includes a file", "include main.fs", []int{9}},
+		{"words from included files stay defined", "include lib/math.fs 2 twice square", []int{16}},
+		{"includes relative to the including file", "include lib/math.fs 5 square", []int{25}},
+		{"rooted paths start at the file system root", "include lib/rooted.fs", []int{8}},
+		{"is case-insensitive", "INCLUDE lib/util.fs 1 TWICE", []int{2}},
+		{"missing file", "include nothing.fs", nil},
+		{"missing path", "include", nil},
+		{"paths can't leave the file system", "include lib/escape.fs", nil},
+		{"files can't include themselves", "include loop/a.fs", nil},
+		{"not allowed in a definition", ": f include lib/util.fs ;", nil},
+		{"can't be redefined", ": include 1 ;", nil},
+	} {
+		in := New(WithFS(includeFS))
+		err := in.Eval(tc.input)
+		checkCase(t, "Eval", tc.description, []string{tc.input}, tc.expected, in.Stack(), err)
+
+		in = New(WithFS(includeFS))
+		p, err := Compile([]string{tc.input})
+		if err == nil {
+			err = in.Run(p)
+		}
+		checkCase(t, "Compile", tc.description, []string{tc.input}, tc.expected, in.Stack(), err)
+	}
+}
+
+func TestIncludeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		input  string
+		target error
+	}{
+		{"include main.fs", ErrNoFileSystem},
+	} {
+		if err := New().Eval(tc.input); !errors.Is(err, tc.target) {
+			t.Fatalf("Eval(%q) expected errors.Is(err, %q), got %v", tc.input, tc.target, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		input  string
+		target error
+	}{
+		{"include", ErrMissingWord},
+		{"include nothing.fs", fs.ErrNotExist},
+		{"include lib/escape.fs", fs.ErrInvalid},
+		{"include loop/a.fs", ErrRecursiveInclude},
+		{"include broken/short.fs", ErrStackUnderflow},
+	} {
+		if err := New(WithFS(includeFS)).Eval(tc.input); !errors.Is(err, tc.target) {
+			t.Fatalf("Eval(%q) expected errors.Is(err, %q), got %v", tc.input, tc.target, err)
+		}
+	}
+}
+
+func TestIncludedErrorsNameTheFile(t *testing.T) {
+	err := New(WithFS(includeFS)).EvalFile("broken/main.fs")
+	expected := "broken/main.fs, line 2, column 9: " +
+		"broken/bad.fs, line 2, column 5: bogus is not a built-in or recognized user-defined word"
+	if err == nil || err.Error() != expected {
+		t.Fatalf("expected %q, got %v", expected, err)
+	}
+	var unknown *UnknownWordError
+	if !errors.As(err, &unknown) || unknown.Line != 2 || unknown.Column != 5 {
+		t.Fatalf("expected an UnknownWordError at line 2, column 5, got %#v", unknown)
+	}
+}
+
+func TestEvalFile(t *testing.T) {
+	in := New(WithFS(includeFS))
+	if err := in.EvalFile("lib/math.fs"); err != nil {
+		t.Fatalf("EvalFile returned an error: %q", err)
+	}
+	if err := in.Eval("3 twice square"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{36}) {
+		t.Fatalf("expected [36], got %v", v)
+	}
+	if err := in.EvalFile("util.fs"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected EvalFile to find files from the root, got %v", err)
+	}
+}
+
+func TestEvalReader(t *testing.T) {
+	in := New(WithFS(includeFS))
+	code := "include lib/util.fs\n: quad ( n -- n )\n  twice\n  twice ;\n3 quad\n"
+	if err := in.EvalReader(strings.NewReader(code)); err != nil {
+		t.Fatalf("EvalReader returned an error: %q", err)
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{12}) {
+		t.Fatalf("expected [12], got %v", v)
+	}
+
+	var evalErr *EvalError
+	err := in.EvalReader(strings.NewReader("1\n2 drop\n  nope"))
+	if !errors.As(err, &evalErr) || evalErr.File != "" || evalErr.Line != 3 || evalErr.Column != 3 {
+		t.Fatalf("expected an error at line 3, column 3, got %v", err)
+	}
+}
+
+// lineReader returns one line of code per Read, first calling before with
+// the number of lines read so far.
+type lineReader struct {
+	lines  []string
+	read   int
+	before func(read int)
+}
+
+func (r *lineReader) Read(p []byte) (int, error) {
+	r.before(r.read)
+	if r.read == len(r.lines) {
+		return 0, io.EOF
+	}
+	r.read++
+	return copy(p, r.lines[r.read-1]+"\n"), nil
+}
+
+func TestEvalReaderStreams(t *testing.T) {
+	in := New()
+	lines := []string{"1", ": f ( n -- n )", "  2 * ;", "f 3 ( a comment", "over ) variable", "x 5 x ! x @", "bogus"}
+	var stacks [][]int
+	r := &lineReader{lines: lines, before: func(int) {
+		stacks = append(stacks, in.Stack())
+	}}
+	err := in.EvalReader(r)
+	var evalErr *EvalError
+	if !errors.As(err, &evalErr) || evalErr.Line != 7 || evalErr.Column != 1 || evalErr.Position != 0 {
+		t.Fatalf("expected an error at line 7, column 1, position 0, got %#v", err)
+	}
+	// The stack before each line is read shows which lines have run.
+	expected := [][]int{{}, {1}, {1}, {1}, {2, 3}, {2, 3}, {2, 3, 5}}
+	if !reflect.DeepEqual(stacks, expected) {
+		t.Fatalf("expected stacks %v, got %v", expected, stacks)
+	}
+}
+
+func TestTraceIncludedFiles(t *testing.T) {
+	var files []string
+	in := New(WithFS(includeFS), WithTrace(func(e TraceEvent) {
+		files = append(files, e.File+" "+e.Word)
+	}))
+	if err := in.Eval("include lib/util.fs 1 twice"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	expected := []string{" 1", " twice", "lib/util.fs 2", "lib/util.fs *"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected %q, got %q", expected, files)
+	}
+}
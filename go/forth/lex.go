@@ -0,0 +1,122 @@
+package forth
+
+import (
+	"strings"
+	"unicode"
+)
+
+// token is a single word of source code along with where it was found.
+// Lines and columns count from 1; columns count runes, not bytes.
+type token struct {
+	text   string
+	line   int
+	column int
+	file   string // the included file the token was read from, if any
+
+	// quoted holds the text of a string literal read by one of the
+	// stringWords, and unclosed reports that it had no closing quote.
+	quoted   string
+	unclosed bool
+}
+
+// stringWords are followed by a string literal running up to the next `"`
+// on the same line, which the lexer keeps whole, whitespace and all. The
+// lexer recognizes them in any case, even when words are case-sensitive,
+// so that code splits into the same tokens however it is evaluated. S" in
+// case-sensitive code is then an unknown word, rather than a word followed
+// by a stray string.
+var stringWords = map[string]bool{
+	`."`: true,
+	`s"`: true,
+}
+
+// source turns tokens back into a line of code.
+func source(tokens []token) string {
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.text
+		if stringWords[strings.ToLower(t.text)] {
+			words[i] += " " + t.quoted + `"`
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// lex turns an array of textual code lines into individual
+// tokens (numbers, operators, etc.).
+// Any run of whitespace separates tokens, and a line may itself contain
+// newlines. `( comments )` and `\ comments` to the end of a line are skipped.
+func lex(s []string) (tokens []token) {
+	line := 0
+	inComment := false
+	for _, text := range s {
+		for _, l := range strings.Split(text, "\n") {
+			line++
+			tokens, inComment = lexLine(tokens, []rune(l), line, inComment)
+		}
+	}
+	return tokens
+}
+
+// lexLine appends the tokens found in a single line of source code.
+// inComment reports whether a `( comment` is still open from an earlier line.
+func lexLine(tokens []token, runes []rune, line int, inComment bool) ([]token, bool) {
+	for i := 0; i < len(runes); {
+		if inComment {
+			end := indexRune(runes, i, ')')
+			if end < 0 {
+				return tokens, true
+			}
+			i = end + 1
+			inComment = false
+			continue
+		}
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			i++
+		}
+		switch word := string(runes[start:i]); word {
+		case "\\":
+			return tokens, false
+		case "(":
+			inComment = true
+		default:
+			t := token{text: word, line: line, column: start + 1}
+			if stringWords[strings.ToLower(word)] {
+				t.quoted, t.unclosed, i = lexString(runes, i)
+			}
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens, inComment
+}
+
+// lexString reads a string literal from just after the whitespace at
+// runes[i] up to the next `"`. It returns the literal, whether the closing
+// quote was missing, and the index to continue lexing from.
+func lexString(runes []rune, i int) (string, bool, int) {
+	if i >= len(runes) {
+		return "", true, i
+	}
+	end := indexRune(runes, i+1, '"')
+	if end < 0 {
+		return string(runes[i+1:]), true, len(runes)
+	}
+	return string(runes[i+1 : end]), false, end + 1
+}
+
+// indexRune returns the index of the first r in runes at or after start,
+// or -1 if there is none.
+func indexRune(runes []rune, start int, r rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,104 @@
+package forth
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       []string
+		expected    []token
+	}{
+		{
+			"splits on single spaces",
+			[]string{"1 dup"},
+			[]token{{text: "1", line: 1, column: 1}, {text: "dup", line: 1, column: 3}},
+		},
+		{
+			"ignores runs of mixed whitespace",
+			[]string{"  1\t\tdup  "},
+			[]token{{text: "1", line: 1, column: 3}, {text: "dup", line: 1, column: 6}},
+		},
+		{
+			"counts lines across input elements and newlines",
+			[]string{"1\n2", "3"},
+			[]token{{text: "1", line: 1, column: 1}, {text: "2", line: 2, column: 1}, {text: "3", line: 3, column: 1}},
+		},
+		{
+			"skips parenthesized comments",
+			[]string{"1 ( a comment ) 2"},
+			[]token{{text: "1", line: 1, column: 1}, {text: "2", line: 1, column: 17}},
+		},
+		{
+			"continues parenthesized comments across lines",
+			[]string{"1 ( a", "comment ) 2"},
+			[]token{{text: "1", line: 1, column: 1}, {text: "2", line: 2, column: 11}},
+		},
+		{
+			"skips backslash comments to the end of the line",
+			[]string{"1 \\ 2 3", "4"},
+			[]token{{text: "1", line: 1, column: 1}, {text: "4", line: 2, column: 1}},
+		},
+		{
+			"only starts comments at whitespace-delimited words",
+			[]string{"(foo) \\bar"},
+			[]token{{text: "(foo)", line: 1, column: 1}, {text: "\\bar", line: 1, column: 7}},
+		},
+		{
+			"counts columns in runes",
+			[]string{"é 1"},
+			[]token{{text: "é", line: 1, column: 1}, {text: "1", line: 1, column: 3}},
+		},
+		{
+			"keeps the string after .\" whole",
+			[]string{`."  hi  there" 1`},
+			[]token{{text: `."`, line: 1, column: 1, quoted: " hi  there"}, {text: "1", line: 1, column: 16}},
+		},
+		{
+			"keeps the string after S\" whole",
+			[]string{`S" a ( b ) \ c" 1`},
+			[]token{{text: `S"`, line: 1, column: 1, quoted: "a ( b ) \\ c"}, {text: "1", line: 1, column: 17}},
+		},
+		{
+			"reports a string without a closing quote",
+			[]string{`." hi`, "1"},
+			[]token{{text: `."`, line: 1, column: 1, quoted: "hi", unclosed: true}, {text: "1", line: 2, column: 1}},
+		},
+		{
+			"returns no tokens for blank input",
+			[]string{"", " \t "},
+			nil,
+		},
+	} {
+		if v := lex(tc.input); !reflect.DeepEqual(v, tc.expected) {
+			t.Fatalf("FAIL: %s\n\tlex(%#v) expected %v, got %v",
+				tc.description, tc.input, tc.expected, v)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
+
+func TestErrorsPointAtSource(t *testing.T) {
+	_, err := Forth([]string{": f ( a b -- ) +  ;", "\t2 3 f", "  f"})
+	var evalErr *EvalError
+	if !errors.As(err, &evalErr) {
+		t.Fatalf("expected an *EvalError, got %v", err)
+	}
+	if evalErr.Line != 3 || evalErr.Column != 3 {
+		t.Fatalf("expected error at line 3, column 3, got line %d, column %d",
+			evalErr.Line, evalErr.Column)
+	}
+
+	_, err = Forth([]string{"1 2", "  foo"})
+	var unknown *UnknownWordError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("expected an *UnknownWordError, got %v", err)
+	}
+	if unknown.Line != 2 || unknown.Column != 3 {
+		t.Fatalf("expected %q at line 2, column 3, got line %d, column %d",
+			unknown.Word, unknown.Line, unknown.Column)
+	}
+}
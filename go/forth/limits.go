@@ -0,0 +1,72 @@
+package forth
+
+import "context"
+
+// DefaultMaxCallDepth is how deeply user-defined words may call each other
+// unless WithMaxCallDepth says otherwise.
+const DefaultMaxCallDepth = 1024
+
+// WithMaxSteps limits how many words, and loop passes, a single evaluation
+// may run before it fails with ErrStepLimit. Zero means no limit.
+func WithMaxSteps(steps int) Option {
+	return func(in *Interpreter) {
+		in.maxSteps = steps
+	}
+}
+
+// WithMaxCallDepth limits how deeply user-defined words may call each other
+// before evaluation fails with ErrCallDepthLimit.
+func WithMaxCallDepth(depth int) Option {
+	return func(in *Interpreter) {
+		in.maxCallDepth = depth
+	}
+}
+
+// evalContext runs tokens with a fresh step count, watching ctx for
+// cancellation.
+func (in *Interpreter) evalContext(ctx context.Context, tokens []token) error {
+	defer in.watch(ctx)()
+	return in.eval(tokens)
+}
+
+// watch starts a fresh step count, watching ctx for cancellation, and
+// returns a func that stops watching. It also empties the transient
+// buffer, as each evaluation starts with its own.
+func (in *Interpreter) watch(ctx context.Context) func() {
+	in.ctx = ctx
+	in.steps = 0
+	in.transient = in.transient[:0]
+	return func() {
+		in.ctx = nil
+	}
+}
+
+// callWord runs the body of a user-defined word, counting it towards
+// WithMaxCallDepth and checking that it leaves the return stack balanced.
+func (in *Interpreter) callWord(body func() error) error {
+	if in.callDepth >= in.maxCallDepth {
+		return ErrCallDepthLimit
+	}
+	in.callDepth++
+	defer func() {
+		in.callDepth--
+	}()
+	return in.runBalanced(body)
+}
+
+// step counts one step of evaluation, failing once the step limit is
+// reached or the context is done.
+func (in *Interpreter) step() error {
+	in.steps++
+	if in.maxSteps > 0 && in.steps > in.maxSteps {
+		return ErrStepLimit
+	}
+	if in.ctx != nil {
+		select {
+		case <-in.ctx.Done():
+			return in.ctx.Err()
+		default:
+		}
+	}
+	return nil
+}
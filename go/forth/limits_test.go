@@ -0,0 +1,81 @@
+package forth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaxSteps(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		line        string
+	}{
+		{"an endless begin until", ": f begin 0 until ; f"},
+		{"a do loop with an empty body", ": f 1000000000 0 do loop ; f"},
+		{"a long top-level program", strings.Repeat("1 drop ", 1000)},
+	} {
+		in := New(WithMaxSteps(100))
+		if err := in.Eval(tc.line); !errors.Is(err, ErrStepLimit) {
+			t.Fatalf("FAIL: %s\n\texpected ErrStepLimit, got %v", tc.description, err)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+
+	in := New(WithMaxSteps(100))
+	for i := 0; i < 10; i++ {
+		if err := in.Eval(strings.Repeat("1 drop ", 25)); err != nil {
+			t.Fatalf("expected the step count to start over for each Eval, got %v", err)
+		}
+	}
+}
+
+func TestMaxCallDepth(t *testing.T) {
+	// Each word calls the one defined before it.
+	lines := []string{": w0 1 ;"}
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf(": w%d w%d ;", i, i-1))
+	}
+
+	in := New(WithMaxCallDepth(10))
+	for _, line := range lines {
+		if err := in.Eval(line); err != nil {
+			t.Fatalf("Eval(%q) returned an error: %q", line, err)
+		}
+	}
+	if err := in.Eval("w9"); err != nil {
+		t.Fatalf("expected w9 to fit in the call depth, got %v", err)
+	}
+	if err := in.Eval("w10"); !errors.Is(err, ErrCallDepthLimit) {
+		t.Fatalf("expected ErrCallDepthLimit, got %v", err)
+	}
+	if err := in.Eval("w9"); err != nil {
+		t.Fatalf("expected the call depth to unwind after an error, got %v", err)
+	}
+}
+
+func TestEvalContext(t *testing.T) {
+	in := New()
+	if err := in.Eval(": forever begin 0 until ;"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := in.EvalContext(ctx, "forever"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := in.EvalContext(ctx, "forever"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if err := in.Eval("1 2 +"); err != nil {
+		t.Fatalf("expected Eval to ignore the earlier context, got %v", err)
+	}
+}
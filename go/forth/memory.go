@@ -0,0 +1,166 @@
+package forth
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DefaultMemorySize is how many cells VARIABLE may allocate unless
+// WithMemorySize says otherwise.
+const DefaultMemorySize = 1 << 16
+
+// defineVariable parses `VARIABLE name`, allocating a cell and defining
+// name to push its address.
+func (in *Interpreter) defineVariable(index *int, lines []token) error {
+	name, err := definedName(index, lines, in.caseSensitive)
+	if err != nil {
+		return err
+	}
+	_, err = in.variable(name)
+	return err
+}
+
+// variable allocates a cell and defines name to push its address,
+// returning the new word.
+func (in *Interpreter) variable(name string) (operation, error) {
+	if len(in.memory) >= in.memorySize {
+		return nil, ErrOutOfMemory
+	}
+	address := len(in.memory)
+	op := func(in *Interpreter) error {
+		return in.stk.Push(address)
+	}
+	in.define(name, op, "variable "+name, address)
+	in.memory = append(in.memory, 0)
+	return op, nil
+}
+
+// defineConstant parses `value CONSTANT name`, defining name to push the
+// value taken from the stack.
+func (in *Interpreter) defineConstant(index *int, lines []token) error {
+	name, err := definedName(index, lines, in.caseSensitive)
+	if err != nil {
+		return err
+	}
+	_, err = in.constant(name)
+	return err
+}
+
+// constant defines name to push the value taken from the stack, returning
+// the new word.
+func (in *Interpreter) constant(name string) (operation, error) {
+	if in.stk.Len() < 1 {
+		return nil, underflow("constant needs a value on the stack")
+	}
+	value, err := in.Pop()
+	if err != nil {
+		return nil, err
+	}
+	op := func(in *Interpreter) error {
+		return in.stk.Push(value)
+	}
+	in.define(name, op, strconv.Itoa(value)+" constant "+name, len(in.memory))
+	return op, nil
+}
+
+// definedName reads the name that follows the defining word at *index and
+// advances *index to it. The name can't be a number, or a word the
+// interpreter parses itself.
+func definedName(index *int, lines []token, caseSensitive bool) (string, error) {
+	if *index+1 >= len(lines) || lines[*index+1].text == ";" {
+		return "", ErrMissingName
+	}
+	*index++
+	name := lines[*index].text
+	if _, err := strconv.Atoi(name); err == nil {
+		return "", ErrRedefineNumber
+	}
+	if name == ":" {
+		return "", ErrNestedDefinition
+	}
+	if key := foldCase(name, caseSensitive); parsingWords[key] || controlWords[key] || stringWords[key] {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	return name, nil
+}
+
+// parsedName reads the word that follows the word at *index, such as SEE,
+// and advances *index to it, returning it as spelled.
+func parsedName(index *int, lines []token) (string, error) {
+	if *index+1 >= len(lines) {
+		return "", fmt.Errorf("%s %w", lines[*index].text, ErrMissingWord)
+	}
+	*index++
+	return lines[*index].text, nil
+}
+
+// cell returns a pointer to the memory cell at address. Addresses from
+// the size of memory up are in the transient buffer.
+func (in *Interpreter) cell(address int) (*int, error) {
+	switch {
+	case address >= 0 && address < len(in.memory):
+		return &in.memory[address], nil
+	case address >= in.memorySize && address-in.memorySize < len(in.transient):
+		return &in.transient[address-in.memorySize], nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrInvalidAddress, address)
+}
+
+func storeOp(in *Interpreter) error {
+	if in.stk.Len() < 2 {
+		return underflow("can't store with ! unless there is a value and an address")
+	}
+	cell, value, err := storeOperands(in)
+	if err != nil {
+		return err
+	}
+	*cell = value
+	return nil
+}
+
+func fetchOp(in *Interpreter) error {
+	if in.stk.Len() < 1 {
+		return underflow("can't fetch with @ without an address")
+	}
+	address, err := in.Pop()
+	if err != nil {
+		return err
+	}
+	cell, err := in.cell(address)
+	if err != nil {
+		return err
+	}
+	return in.Push(*cell)
+}
+
+func addStoreOp(in *Interpreter) error {
+	if in.stk.Len() < 2 {
+		return underflow("can't add with +! unless there is a value and an address")
+	}
+	cell, n, err := storeOperands(in)
+	if err != nil {
+		return err
+	}
+	sum, overflow := addInts(*cell, n)
+	if overflow && in.stk.mode != WrapMode {
+		return ErrOverflow
+	}
+	*cell = sum
+	return nil
+}
+
+// storeOperands pops the address and, below it, the value that ! and +!
+// take, returning the cell at the address. Both are popped even when the
+// address is invalid, as / and MOD drop their operands on error.
+func storeOperands(in *Interpreter) (*int, int, error) {
+	address, addressErr := in.stk.PopInt()
+	value, err := in.stk.PopInt()
+	if addressErr != nil {
+		return nil, 0, addressErr
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	cell, err := in.cell(address)
+	return cell, value, err
+}
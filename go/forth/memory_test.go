@@ -0,0 +1,147 @@
+package forth
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var memoryTestGroups = []testGroup{
+	{
+		group: "variable",
+		tests: []testCase{
+			{
+				"starts at zero",
+				[]string{"variable x", "x @"},
+				[]int{0},
+			},
+			{
+				"stores and fetches a value",
+				[]string{"variable x", "42 x !", "x @"},
+				[]int{42},
+			},
+			{
+				"adds to a stored value",
+				[]string{"variable x", "40 x ! 2 x +!", "x @"},
+				[]int{42},
+			},
+			{
+				"gets its own cell",
+				[]string{"variable x variable y", "1 x ! 2 y !", "x @ y @"},
+				[]int{1, 2},
+			},
+			{
+				"can be used inside a definition",
+				[]string{"variable count", ": bump 1 count +! ;", "bump bump count @"},
+				[]int{2},
+			},
+			{
+				"is case-insensitive",
+				[]string{"VARIABLE X", "7 x !", "X @"},
+				[]int{7},
+			},
+			{
+				"errors without a name",
+				[]string{"variable"},
+				[]int(nil),
+			},
+			{
+				"cannot redefine numbers",
+				[]string{"variable 1"},
+				[]int(nil),
+			},
+			{
+				"errors inside a definition",
+				[]string{": f variable x ;"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "constant",
+		tests: []testCase{
+			{
+				"pushes its value",
+				[]string{"42 constant answer", "answer answer"},
+				[]int{42, 42},
+			},
+			{
+				"takes its value from the top of the stack",
+				[]string{"1 2 constant two", "two"},
+				[]int{1, 2},
+			},
+			{
+				"errors if there is nothing on the stack",
+				[]string{"constant answer"},
+				[]int(nil),
+			},
+			{
+				"errors without a name",
+				[]string{"1 constant"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "fetch and store",
+		tests: []testCase{
+			{
+				"! errors if there is only one value on the stack",
+				[]string{"variable x", "x !"},
+				[]int(nil),
+			},
+			{
+				"@ errors if there is nothing on the stack",
+				[]string{"@"},
+				[]int(nil),
+			},
+			{
+				"+! errors if there is only one value on the stack",
+				[]string{"variable x", "x +!"},
+				[]int(nil),
+			},
+			{
+				"@ errors on an unallocated address",
+				[]string{"variable x", "x 1 + @"},
+				[]int(nil),
+			},
+			{
+				"! errors on a negative address",
+				[]string{"1 -1 !"},
+				[]int(nil),
+			},
+		},
+	},
+}
+
+func TestMemoryWords(t *testing.T) {
+	runTestGroups(t, memoryTestGroups)
+}
+
+func TestMemoryErrors(t *testing.T) {
+	if _, err := Forth([]string{"variable x", "x 5 + @"}); !errors.Is(err, ErrInvalidAddress) {
+		t.Fatalf("expected ErrInvalidAddress, got %v", err)
+	}
+	for _, line := range []string{"1 2 5 !", "1 2 5 +!"} {
+		v, err := Forth([]string{"variable x", line})
+		if !errors.Is(err, ErrInvalidAddress) {
+			t.Fatalf("Forth(%q) expected ErrInvalidAddress, got %v", line, err)
+		}
+		if !reflect.DeepEqual(v, []int{1}) {
+			t.Fatalf("Forth(%q) expected both operands dropped, leaving [1], got %v", line, v)
+		}
+	}
+
+	in := New(WithMemorySize(1))
+	if err := in.Eval("variable x"); err != nil {
+		t.Fatalf("Eval(%q) returned an error: %q", "variable x", err)
+	}
+	if err := in.Eval("variable y"); !errors.Is(err, ErrOutOfMemory) {
+		t.Fatalf("expected ErrOutOfMemory, got %v", err)
+	}
+
+	in.Reset()
+	if err := in.Eval("variable y"); err != nil {
+		t.Fatalf("Eval(%q) after Reset() returned an error: %q", "variable y", err)
+	}
+}
@@ -0,0 +1,205 @@
+package forth
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// Mode chooses what arithmetic does when a result doesn't fit in an int.
+type Mode int
+
+const (
+	// WrapMode wraps around silently, as Go's int does. It is the default.
+	WrapMode Mode = iota
+	// CheckedMode fails with ErrOverflow instead of wrapping.
+	CheckedMode
+	// BigMode keeps growing values exactly using math/big. Arithmetic,
+	// comparison and output words accept them, and stack words move them
+	// around, but words that need an address, index, character or flag
+	// fail with ErrOverflow if given one.
+	BigMode
+)
+
+func (m Mode) String() string {
+	switch m {
+	case WrapMode:
+		return "wrap"
+	case CheckedMode:
+		return "checked"
+	case BigMode:
+		return "big"
+	}
+	return "unknown"
+}
+
+// WithMode sets how arithmetic handles results that don't fit in an int.
+func WithMode(mode Mode) Option {
+	return func(in *Interpreter) {
+		in.stk.mode = mode
+	}
+}
+
+// BigStack returns a copy of the data stack, bottom value first, with
+// every value exact even if it doesn't fit in an int.
+func (in *Interpreter) BigStack() []*big.Int {
+	results := make([]*big.Int, in.stk.Len())
+	for i := range results {
+		results[i] = new(big.Int).Set(in.stk.bigAt(i))
+	}
+	return results
+}
+
+// binaryOp replaces the top two values a and b with the result of a word.
+// small computes the result for ints, reporting whether it overflowed,
+// and large computes it exactly for when it did.
+func (s *intStack) binaryOp(small func(a, b int) (int, bool), large func(a, b *big.Int) *big.Int) error {
+	if s.isBig(0) || s.isBig(1) {
+		b := s.PopBig()
+		a := s.PopBig()
+		return s.PushBig(large(a, b))
+	}
+	b := s.Pop()
+	a := s.Pop()
+	result, overflow := small(a, b)
+	if overflow {
+		switch s.mode {
+		case CheckedMode:
+			return ErrOverflow
+		case BigMode:
+			return s.PushBig(large(big.NewInt(int64(a)), big.NewInt(int64(b))))
+		}
+	}
+	return s.Push(result)
+}
+
+// unaryOp replaces the top value with the result of a word, like binaryOp.
+func (s *intStack) unaryOp(small func(a int) (int, bool), large func(a *big.Int) *big.Int) error {
+	if s.isBig(0) {
+		return s.PushBig(large(s.PopBig()))
+	}
+	a := s.Pop()
+	result, overflow := small(a)
+	if overflow {
+		switch s.mode {
+		case CheckedMode:
+			return ErrOverflow
+		case BigMode:
+			return s.PushBig(large(big.NewInt(int64(a))))
+		}
+	}
+	return s.Push(result)
+}
+
+// popCompare removes the top two values a and b, returning -1, 0 or +1 as
+// a is less than, equal to or greater than b.
+func (s *intStack) popCompare() int {
+	cmp := s.compareTop()
+	s.Pop()
+	s.Pop()
+	return cmp
+}
+
+// compareTop compares the top two values a and b like popCompare, but
+// leaves them on the stack.
+func (s *intStack) compareTop() int {
+	if s.isBig(0) || s.isBig(1) {
+		return s.bigAt(s.Len() - 2).Cmp(s.bigAt(s.Len() - 1))
+	}
+	a, b := s.Pick(1), s.Pick(0)
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// fitsInt returns z as an int if it fits in one.
+func fitsInt(z *big.Int) (int, bool) {
+	if !z.IsInt64() || z.Int64() < math.MinInt || z.Int64() > math.MaxInt {
+		return 0, false
+	}
+	return int(z.Int64()), true
+}
+
+func addInts(a, b int) (int, bool) {
+	sum := a + b
+	return sum, (a >= 0) == (b >= 0) && (sum >= 0) != (a >= 0)
+}
+
+func subtractInts(a, b int) (int, bool) {
+	difference := a - b
+	return difference, (a >= 0) != (b >= 0) && (difference >= 0) != (a >= 0)
+}
+
+func multiplyInts(a, b int) (int, bool) {
+	product := a * b
+	return product, a != 0 && (product/a != b || (a == -1 && b == math.MinInt))
+}
+
+func divideInts(a, b int) (int, bool) {
+	return a / b, a == math.MinInt && b == -1
+}
+
+func remainderInts(a, b int) (int, bool) {
+	return a % b, false
+}
+
+func negateInt(a int) (int, bool) {
+	return -a, a == math.MinInt
+}
+
+func absInt(a int) (int, bool) {
+	if a < 0 {
+		return negateInt(a)
+	}
+	return a, false
+}
+
+func bitwiseInts(op func(a, b int) int) func(a, b int) (int, bool) {
+	return func(a, b int) (int, bool) {
+		return op(a, b), false
+	}
+}
+
+func addBigs(a, b *big.Int) *big.Int {
+	return new(big.Int).Add(a, b)
+}
+
+func subtractBigs(a, b *big.Int) *big.Int {
+	return new(big.Int).Sub(a, b)
+}
+
+func multiplyBigs(a, b *big.Int) *big.Int {
+	return new(big.Int).Mul(a, b)
+}
+
+func divideBigs(a, b *big.Int) *big.Int {
+	return new(big.Int).Quo(a, b)
+}
+
+func remainderBigs(a, b *big.Int) *big.Int {
+	return new(big.Int).Rem(a, b)
+}
+
+func negateBig(a *big.Int) *big.Int {
+	return new(big.Int).Neg(a)
+}
+
+func absBig(a *big.Int) *big.Int {
+	return new(big.Int).Abs(a)
+}
+
+// parseBig parses word as a number too large for an int, given the error
+// strconv.Atoi returned for it. Atoi reports ErrRange as soon as the digits
+// overflow, before looking at the rest of the word, so the whole word still
+// has to be checked.
+func parseBig(word string, err error) (*big.Int, bool) {
+	if !errors.Is(err, strconv.ErrRange) {
+		return nil, false
+	}
+	return new(big.Int).SetString(word, 10)
+}
@@ -0,0 +1,155 @@
+package forth
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+var (
+	maxInt = strconv.Itoa(math.MaxInt)
+	minInt = strconv.Itoa(math.MinInt)
+)
+
+func TestOverflowByMode(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       string
+		wrapped     int
+		exact       string
+	}{
+		{"addition", maxInt + " 1 +", math.MinInt, "9223372036854775808"},
+		{"subtraction", minInt + " 1 -", math.MaxInt, "-9223372036854775809"},
+		{"multiplication", maxInt + " 2 *", -2, "18446744073709551614"},
+		{"multiplication of the smallest int by -1", minInt + " -1 *", math.MinInt, "9223372036854775808"},
+		{"division of the smallest int by -1", minInt + " -1 /", math.MinInt, "9223372036854775808"},
+		{"/mod of the smallest int by -1", minInt + " -1 /mod nip", math.MinInt, "9223372036854775808"},
+		{"negation of the smallest int", minInt + " negate", math.MinInt, "9223372036854775808"},
+		{"abs of the smallest int", minInt + " abs", math.MinInt, "9223372036854775808"},
+	} {
+		in := New()
+		if err := in.Eval(tc.input); err != nil {
+			t.Fatalf("FAIL: %s | wrap mode\n\tEval(%q) returned an error: %q", tc.description, tc.input, err)
+		}
+		if v := in.Stack(); len(v) != 1 || v[0] != tc.wrapped {
+			t.Fatalf("FAIL: %s | wrap mode\n\tEval(%q) expected [%d], got %v", tc.description, tc.input, tc.wrapped, v)
+		}
+
+		in = New(WithMode(CheckedMode))
+		if err := in.Eval(tc.input); !errors.Is(err, ErrOverflow) {
+			t.Fatalf("FAIL: %s | checked mode\n\tEval(%q) expected ErrOverflow, got %v", tc.description, tc.input, err)
+		}
+
+		in = New(WithMode(BigMode))
+		if err := in.Eval(tc.input); err != nil {
+			t.Fatalf("FAIL: %s | big mode\n\tEval(%q) returned an error: %q", tc.description, tc.input, err)
+		}
+		if v := in.BigStack(); len(v) != 1 || v[0].String() != tc.exact {
+			t.Fatalf("FAIL: %s | big mode\n\tEval(%q) expected [%s], got %v", tc.description, tc.input, tc.exact, v)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
+
+func TestBigMode(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       string
+		expected    string
+	}{
+		{"shrinks back to an int", maxInt + " 1 + 1 -", maxInt},
+		{"computes factorials", ": fact dup 1 > if dup 1 - recurse * then ; 25 fact", "15511210043330985984000000"},
+		{"reads large literals", "100000000000000000000 3 /", "33333333333333333333"},
+		{"moves large values with stack words", "100000000000000000000 1 swap over + nip", "100000000000000000001"},
+		{"copies large values with stack words", "100000000000000000000 1 2dup 2swap drop tuck", "100000000000000000000 100000000000000000000 1 100000000000000000000"},
+		{"compares large values", "100000000000000000000 dup 1 + <", "-1"},
+		{"tests large values with 0=", "100000000000000000000 0=", "0"},
+		{"branches on large values", ": f if 1 else 2 then ; 100000000000000000000 f", "1"},
+		{"inverts large values", "100000000000000000000 invert", "-100000000000000000001"},
+		{"takes remainders of large values", "-100000000000000000001 10 mod", "-1"},
+		{"divides large values with /mod", "-100000000000000000001 10 /mod", "-1 -10000000000000000000"},
+		{"negates large values", "100000000000000000000 negate", "-100000000000000000000"},
+		{"takes abs of large values", "-100000000000000000000 abs", "100000000000000000000"},
+		{"keeps the smaller large value", "100000000000000000000 100000000000000000001 min", "100000000000000000000"},
+		{"keeps the larger large value", "1 100000000000000000000 max", "100000000000000000000"},
+	} {
+		in := New(WithMode(BigMode))
+		if err := in.Eval(tc.input); err != nil {
+			t.Fatalf("FAIL: %s\n\tEval(%q) returned an error: %q", tc.description, tc.input, err)
+		}
+		text := ""
+		for i, v := range in.BigStack() {
+			if i > 0 {
+				text += " "
+			}
+			text += v.String()
+		}
+		if text != tc.expected {
+			t.Fatalf("FAIL: %s\n\tEval(%q) expected %s, got %s", tc.description, tc.input, tc.expected, text)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
+
+func TestBigModeOutput(t *testing.T) {
+	var out bytes.Buffer
+	in := New(WithMode(BigMode), WithOutput(&out))
+	if err := in.Eval("100000000000000000000 1 .s . ."); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	expected := "<2> 100000000000000000000 1 1 100000000000000000000 "
+	if out.String() != expected {
+		t.Fatalf("expected output %q, got %q", expected, out.String())
+	}
+}
+
+func TestBigModeNeedsIntsForAddresses(t *testing.T) {
+	for _, line := range []string{
+		"variable x 100000000000000000000 x !",
+		"100000000000000000000 @",
+		"100000000000000000000 emit",
+		": f 100000000000000000000 >r ; f",
+		"1 100000000000000000000 pick",
+	} {
+		in := New(WithMode(BigMode))
+		if err := in.Eval(line); !errors.Is(err, ErrOverflow) {
+			t.Fatalf("Eval(%q) expected ErrOverflow, got %v", line, err)
+		}
+	}
+}
+
+func TestLargeNumberLikeWordsAreWords(t *testing.T) {
+	for _, mode := range modes {
+		in := New(WithMode(mode))
+		var unknown *UnknownWordError
+		if err := in.Eval("2000000000000000000000A"); !errors.As(err, &unknown) {
+			t.Fatalf("%s mode expected an UnknownWordError, got %v", mode, err)
+		}
+		if err := in.Eval(": 2000000000000000000000A 1 ; 2000000000000000000000a"); err != nil {
+			t.Fatalf("%s mode returned an error defining the word: %q", mode, err)
+		}
+	}
+}
+
+func TestLargeLiteralsOverflowOutsideBigMode(t *testing.T) {
+	for _, mode := range []Mode{WrapMode, CheckedMode} {
+		in := New(WithMode(mode))
+		if err := in.Eval("100000000000000000000"); !errors.Is(err, ErrOverflow) {
+			t.Fatalf("%s mode expected ErrOverflow, got %v", mode, err)
+		}
+	}
+}
+
+func TestBigStackCopies(t *testing.T) {
+	in := New(WithMode(BigMode))
+	if err := in.Eval("100000000000000000000"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	in.BigStack()[0].SetInt64(0)
+	if v := in.BigStack()[0]; v.Cmp(big.NewInt(0)) == 0 {
+		t.Fatalf("expected BigStack to return copies")
+	}
+}
@@ -0,0 +1,78 @@
+package forth
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// compileString turns a word such as `."` and the string literal the lexer
+// read after it into an operation.
+func (in *Interpreter) compileString(word string, t token) (operation, error) {
+	if t.unclosed {
+		return nil, fmt.Errorf("%s %w", t.text, ErrUnclosedString)
+	}
+	if word == `s"` {
+		return in.compileStringLiteral(t.quoted)
+	}
+	text := t.quoted
+	return func(in *Interpreter) error {
+		_, err := io.WriteString(in.out, text)
+		return err
+	}, nil
+}
+
+// printOp prints the top value followed by a space.
+func printOp(in *Interpreter) error {
+	if in.stk.Len() < 1 {
+		return underflow("can't print with . without an argument")
+	}
+	text := in.stk.text(in.stk.Len() - 1)
+	in.stk.Pop()
+	_, err := io.WriteString(in.out, text+" ")
+	return err
+}
+
+// printStackOp prints the depth and contents of the stack, bottom value
+// first, without changing it.
+func printStackOp(in *Interpreter) error {
+	text := "<" + strconv.Itoa(in.stk.Len()) + "> "
+	for i := range in.stk.values {
+		text += in.stk.text(i) + " "
+	}
+	_, err := io.WriteString(in.out, text)
+	return err
+}
+
+// emitOp prints the character whose code is the top value.
+func emitOp(in *Interpreter) error {
+	if in.stk.Len() < 1 {
+		return underflow("can't emit without a character code")
+	}
+	code, err := in.Pop()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(in.out, string(rune(code)))
+	return err
+}
+
+// typeOp prints the string whose address and length are on top of the
+// stack, one character per memory cell.
+func typeOp(in *Interpreter) error {
+	if in.stk.Len() < 2 {
+		return underflow("can't type unless there is an address and a length")
+	}
+	text, err := in.PopString()
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(in.out, text)
+	return err
+}
+
+// crOp starts a new line of output.
+func crOp(in *Interpreter) error {
+	_, err := io.WriteString(in.out, "\n")
+	return err
+}
@@ -0,0 +1,67 @@
+package forth
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestOutputWords(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       []string
+		output      string
+		stack       []int
+	}{
+		{"prints and pops the top value", []string{"1 2 ."}, "2 ", []int{1}},
+		{"prints negative numbers", []string{"-5 ."}, "-5 ", []int{}},
+		{"emits a character", []string{"72 emit 105 EMIT"}, "Hi", []int{}},
+		{"starts a new line", []string{"1 . cr 2 ."}, "1 \n2 ", []int{}},
+		{"prints a string", []string{`." Hello, World!"`}, "Hello, World!", []int{}},
+		{"keeps whitespace inside a string", []string{`."  two  spaces "`}, " two  spaces ", []int{}},
+		{"prints a string from a definition", []string{`: greet ." hi" cr ;`, "greet greet"}, "hi\nhi\n", []int{}},
+		{"prints the stack without changing it", []string{"1 2 3 .s"}, "<3> 1 2 3 ", []int{1, 2, 3}},
+		{"prints an empty stack", []string{".s"}, "<0> ", []int{}},
+	} {
+		var out bytes.Buffer
+		in := New(WithOutput(&out))
+		for _, line := range tc.input {
+			if err := in.Eval(line); err != nil {
+				t.Fatalf("FAIL: %s\n\tEval(%q) returned an error: %q", tc.description, line, err)
+			}
+		}
+		if out.String() != tc.output {
+			t.Fatalf("FAIL: %s\n\t%#v expected output %q, got %q", tc.description, tc.input, tc.output, out.String())
+		}
+		if v := in.Stack(); !reflect.DeepEqual(v, tc.stack) {
+			t.Fatalf("FAIL: %s\n\t%#v expected stack %v, got %v", tc.description, tc.input, tc.stack, v)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
+
+func TestOutputErrors(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       []string
+		target      error
+	}{
+		{". errors if there is nothing on the stack", []string{"."}, ErrStackUnderflow},
+		{"emit errors if there is nothing on the stack", []string{"emit"}, ErrStackUnderflow},
+		{"a string errors without a closing quote", []string{`." oops`}, ErrUnclosedString},
+		{"a string errors without a closing quote in a definition", []string{`: f ." oops ;`}, ErrUnclosedString},
+	} {
+		if _, err := Forth(tc.input); !errors.Is(err, tc.target) {
+			t.Fatalf("FAIL: %s\n\tForth(%#v) expected errors.Is(err, %q), got %v",
+				tc.description, tc.input, tc.target, err)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
+
+func TestOutputIsDiscardedByDefault(t *testing.T) {
+	if v, err := Forth([]string{`1 . ." ignored" cr`}); err != nil || len(v) != 0 {
+		t.Fatalf("expected an empty stack and no error, got %v, %v", v, err)
+	}
+}
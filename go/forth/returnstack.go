@@ -0,0 +1,46 @@
+package forth
+
+// The return stack gives words somewhere to keep values out of the way of
+// the data stack. A word must leave it as deep as it found it.
+
+// runBalanced runs the body of a user-defined word, checking that it leaves
+// the return stack as deep as it found it. Whatever the word left behind is
+// dropped so that callers see the return stack they expect.
+func (in *Interpreter) runBalanced(body func() error) error {
+	depth := in.rstk.Len()
+	err := body()
+	if in.rstk.Len() != depth {
+		if in.rstk.Len() > depth {
+			in.rstk.values = in.rstk.values[:depth]
+		}
+		if err == nil {
+			err = ErrReturnStackImbalance
+		}
+	}
+	return err
+}
+
+func toReturnOp(in *Interpreter) error {
+	if in.stk.Len() < 1 {
+		return underflow("can't move with >r without an argument")
+	}
+	n, err := in.stk.PopInt()
+	if err != nil {
+		return err
+	}
+	return in.rstk.Push(n)
+}
+
+func fromReturnOp(in *Interpreter) error {
+	if in.rstk.Len() < 1 {
+		return underflow("can't move with r> from an empty return stack")
+	}
+	return in.stk.Push(in.rstk.Pop())
+}
+
+func copyReturnOp(in *Interpreter) error {
+	if in.rstk.Len() < 1 {
+		return underflow("can't copy with r@ from an empty return stack")
+	}
+	return in.stk.Push(in.rstk.Pick(0))
+}
@@ -0,0 +1,101 @@
+package forth
+
+import (
+	"errors"
+	"testing"
+)
+
+var returnStackTestGroups = []testGroup{
+	{
+		group: "return stack",
+		tests: []testCase{
+			{
+				">r and r> move a value out of the way",
+				[]string{": under+ >r + r> ;", "1 2 3 under+"},
+				[]int{3, 3},
+			},
+			{
+				"r@ copies without removing",
+				[]string{": f >r r@ r@ + r> ;", "5 f"},
+				[]int{10, 5},
+			},
+			{
+				"is case-insensitive",
+				[]string{": f >R R@ R> ;", "7 f"},
+				[]int{7, 7},
+			},
+			{
+				">r errors if there is nothing on the stack",
+				[]string{": f >r ;", "f"},
+				[]int(nil),
+			},
+			{
+				"r> errors if the return stack is empty",
+				[]string{": f r> ;", "f"},
+				[]int(nil),
+			},
+			{
+				"r@ errors if the return stack is empty",
+				[]string{": f r@ ;", "f"},
+				[]int(nil),
+			},
+			{
+				"errors if a word leaves values on the return stack",
+				[]string{": f >r ;", "1 f"},
+				[]int(nil),
+			},
+			{
+				"errors if a word takes values it did not put on the return stack",
+				[]string{": g r> ;", ": f >r g ;", "1 f"},
+				[]int(nil),
+			},
+			{
+				"errors outside of a definition",
+				[]string{"1 >r"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "recurse",
+		tests: []testCase{
+			{
+				"calls the word being defined",
+				[]string{": fact dup 1 > if dup 1 - recurse * then ;", "5 fact"},
+				[]int{120},
+			},
+			{
+				"calls the new definition rather than an older one",
+				[]string{": f 0 ;", ": f dup 0 > if 1 - recurse then ;", "3 f"},
+				[]int{0},
+			},
+			{
+				"errors outside of a definition",
+				[]string{"recurse"},
+				[]int(nil),
+			},
+		},
+	},
+}
+
+func TestReturnStackWords(t *testing.T) {
+	runTestGroups(t, returnStackTestGroups)
+}
+
+func TestReturnStackErrors(t *testing.T) {
+	if _, err := Forth([]string{": f >r ;", "1 f"}); !errors.Is(err, ErrReturnStackImbalance) {
+		t.Fatalf("expected ErrReturnStackImbalance, got %v", err)
+	}
+	if _, err := Forth([]string{": f recurse ;", "f"}); !errors.Is(err, ErrCallDepthLimit) {
+		t.Fatalf("expected endless recursion to fail with ErrCallDepthLimit, got %v", err)
+	}
+
+	in := New()
+	if err := in.Eval(": leak >r ;"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	in.Eval("1 leak")
+	if err := in.Eval(": f 2 >r r> ; f"); err != nil {
+		t.Fatalf("expected the return stack to be cleaned up after an error, got %v", err)
+	}
+}
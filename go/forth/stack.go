@@ -0,0 +1,150 @@
+package forth
+
+import "math/big"
+
+// DefaultMaxStackDepth is how many values the data stack holds unless
+// WithMaxStackDepth says otherwise.
+const DefaultMaxStackDepth = 1 << 16
+
+// intStack is a last-in, first-out stack of integers with a maximum depth.
+// Callers check Len before popping, as every word reports its own underflow.
+//
+// In BigMode, values that outgrow an int are kept exactly in bigs, while
+// values holds their low bits. Everywhere else bigs is unused.
+type intStack struct {
+	values   []int
+	bigs     []*big.Int
+	mode     Mode
+	maxDepth int
+}
+
+// Len returns the number of values on the stack.
+func (s *intStack) Len() int {
+	return len(s.values)
+}
+
+// Push adds values to the top of the stack, the last one ending up on top.
+// Nothing is pushed if the stack would grow past its maximum depth.
+func (s *intStack) Push(values ...int) error {
+	if len(s.values)+len(values) > s.maxDepth {
+		return ErrStackOverflow
+	}
+	s.values = append(s.values, values...)
+	if s.mode == BigMode {
+		s.bigs = append(s.bigs, make([]*big.Int, len(values))...)
+	}
+	return nil
+}
+
+// PushBig adds z to the top of the stack. Only BigMode can hold values
+// that don't fit in an int; other modes fail with ErrOverflow.
+func (s *intStack) PushBig(z *big.Int) error {
+	if n, ok := fitsInt(z); ok {
+		return s.Push(n)
+	}
+	if s.mode != BigMode {
+		return ErrOverflow
+	}
+	if len(s.values)+1 > s.maxDepth {
+		return ErrStackOverflow
+	}
+	s.values = append(s.values, int(z.Int64()))
+	s.bigs = append(s.bigs, z)
+	return nil
+}
+
+// Pop removes and returns the top value. A value that outgrew an int comes
+// back wrapped, so words that need the exact value use PopInt or PopBig.
+func (s *intStack) Pop() int {
+	n := len(s.values) - 1
+	top := s.values[n]
+	s.values = s.values[:n]
+	if s.mode == BigMode {
+		s.bigs[n] = nil
+		s.bigs = s.bigs[:n]
+	}
+	return top
+}
+
+// PopInt removes the top value and returns it, failing with ErrOverflow if
+// it doesn't fit in an int.
+func (s *intStack) PopInt() (int, error) {
+	if s.isBig(0) {
+		s.Pop()
+		return 0, ErrOverflow
+	}
+	return s.Pop(), nil
+}
+
+// PopBig removes the top value and returns it exactly.
+func (s *intStack) PopBig() *big.Int {
+	z := s.bigAt(len(s.values) - 1)
+	s.Pop()
+	return z
+}
+
+// Pick returns the value n places below the top without removing it,
+// so Pick(0) is the top value.
+func (s *intStack) Pick(n int) int {
+	return s.values[len(s.values)-1-n]
+}
+
+// Copy pushes copies of the values n places below the top, each counted
+// from the stack as it was before copying. Nothing is pushed if the stack
+// would grow past its maximum depth.
+func (s *intStack) Copy(ns ...int) error {
+	if len(s.values)+len(ns) > s.maxDepth {
+		return ErrStackOverflow
+	}
+	top := len(s.values) - 1
+	for _, n := range ns {
+		s.values = append(s.values, s.values[top-n])
+		if s.mode == BigMode {
+			s.bigs = append(s.bigs, s.bigs[top-n])
+		}
+	}
+	return nil
+}
+
+// Roll moves the value n places below the top up to the top, shifting the
+// values above it down, so Roll(1) swaps the top two values.
+func (s *intStack) Roll(n int) {
+	i := len(s.values) - 1 - n
+	rolled := s.values[i]
+	copy(s.values[i:], s.values[i+1:])
+	s.values[len(s.values)-1] = rolled
+	if s.mode == BigMode {
+		rolledBig := s.bigs[i]
+		copy(s.bigs[i:], s.bigs[i+1:])
+		s.bigs[len(s.bigs)-1] = rolledBig
+	}
+}
+
+// Clear removes every value, keeping the maximum depth.
+func (s *intStack) Clear() {
+	s.values = s.values[:0]
+	s.bigs = nil
+}
+
+// isBig reports whether the value n places below the top outgrew an int.
+func (s *intStack) isBig(n int) bool {
+	return s.mode == BigMode && s.bigs[len(s.bigs)-1-n] != nil
+}
+
+// isZero reports whether the value n places below the top is zero.
+func (s *intStack) isZero(n int) bool {
+	return !s.isBig(n) && s.Pick(n) == 0
+}
+
+// bigAt returns the value at index i, counting from the bottom, exactly.
+func (s *intStack) bigAt(i int) *big.Int {
+	if s.mode == BigMode && s.bigs[i] != nil {
+		return s.bigs[i]
+	}
+	return big.NewInt(int64(s.values[i]))
+}
+
+// text formats the value at index i, counting from the bottom.
+func (s *intStack) text(i int) string {
+	return s.bigAt(i).String()
+}
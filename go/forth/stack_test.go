@@ -0,0 +1,137 @@
+package forth
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMaxStackDepth(t *testing.T) {
+	in := New(WithMaxStackDepth(3))
+	if err := in.Eval("1 2 3"); err != nil {
+		t.Fatalf("Eval(%q) returned an error: %q", "1 2 3", err)
+	}
+	for _, line := range []string{"4", "dup", "over", "tuck", "2dup", "depth"} {
+		if err := in.Eval(line); !errors.Is(err, ErrStackOverflow) {
+			t.Fatalf("Eval(%q) on a full stack expected ErrStackOverflow, got %v", line, err)
+		}
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{1, 2, 3}) {
+		t.Fatalf("Stack() after overflow expected %v, got %v", []int{1, 2, 3}, v)
+	}
+
+	in.Reset()
+	if err := in.Eval("1 2 3 4"); !errors.Is(err, ErrStackOverflow) {
+		t.Fatalf("Reset() expected to keep the maximum depth, got %v", err)
+	}
+}
+
+// longProgram counts up to n, shuffling the stack between each step.
+func longProgram(n int) []string {
+	return []string{"0" + strings.Repeat(" dup drop 1 +", n)}
+}
+
+func BenchmarkLongProgram(b *testing.B) {
+	program := longProgram(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Forth(program)
+	}
+}
+
+func BenchmarkDeepStack(b *testing.B) {
+	program := []string{strings.Repeat("1 ", 10000) + strings.Repeat("+ ", 9999)}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Forth(program)
+	}
+}
+
+// boxedStack is the stack the interpreter used before intStack: a linked
+// list of interface values, as in github.com/golang-collections/collections,
+// which every pop had to type-assert. It is kept as the baseline for
+// BenchmarkStack.
+type boxedStack struct {
+	top    *boxedNode
+	length int
+}
+
+type boxedNode struct {
+	value interface{}
+	prev  *boxedNode
+}
+
+func (s *boxedStack) Push(value interface{}) {
+	s.top = &boxedNode{value, s.top}
+	s.length++
+}
+
+func (s *boxedStack) Pop() interface{} {
+	if s.length == 0 {
+		return nil
+	}
+	n := s.top
+	s.top = n.prev
+	s.length--
+	return n.value
+}
+
+func (s *boxedStack) Peek() interface{} {
+	if s.length == 0 {
+		return nil
+	}
+	return s.top.value
+}
+
+// BenchmarkStack compares intStack with the boxed stack it replaced, on the
+// work longProgram does and on the deep stack of BenchmarkDeepStack.
+func BenchmarkStack(b *testing.B) {
+	const n = 10000
+	b.Run("long/boxed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var s boxedStack
+			s.Push(0)
+			for j := 0; j < n; j++ {
+				s.Push(s.Peek())
+				s.Pop()
+				s.Push(1)
+				s.Push(s.Pop().(int) + s.Pop().(int))
+			}
+		}
+	})
+	b.Run("long/int", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s := intStack{maxDepth: DefaultMaxStackDepth}
+			s.Push(0)
+			for j := 0; j < n; j++ {
+				s.Push(s.Pick(0))
+				s.Pop()
+				s.Push(1)
+				s.Push(s.Pop() + s.Pop())
+			}
+		}
+	})
+	b.Run("deep/boxed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var s boxedStack
+			for j := 0; j < n; j++ {
+				s.Push(1)
+			}
+			for s.length > 1 {
+				s.Push(s.Pop().(int) + s.Pop().(int))
+			}
+		}
+	})
+	b.Run("deep/int", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			s := intStack{maxDepth: DefaultMaxStackDepth}
+			for j := 0; j < n; j++ {
+				s.Push(1)
+			}
+			for s.Len() > 1 {
+				s.Push(s.Pop() + s.Pop())
+			}
+		}
+	})
+}
@@ -0,0 +1,230 @@
+package forth
+
+import "testing"
+
+var stackWordTestGroups = []testGroup{
+	{
+		group: "rot",
+		tests: []testCase{
+			{
+				"rotates the third value to the top",
+				[]string{"1 2 3 rot"},
+				[]int{2, 3, 1},
+			},
+			{
+				"leaves values below the top three alone",
+				[]string{"0 1 2 3 rot"},
+				[]int{0, 2, 3, 1},
+			},
+			{
+				"is case-insensitive",
+				[]string{"1 2 3 ROT"},
+				[]int{2, 3, 1},
+			},
+			{
+				"errors if there are only two values on the stack",
+				[]string{"1 2 rot"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "-rot",
+		tests: []testCase{
+			{
+				"rotates the top value to third",
+				[]string{"1 2 3 -rot"},
+				[]int{3, 1, 2},
+			},
+			{
+				"undoes rot",
+				[]string{"1 2 3 rot -rot"},
+				[]int{1, 2, 3},
+			},
+			{
+				"errors if there are only two values on the stack",
+				[]string{"1 2 -rot"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "nip",
+		tests: []testCase{
+			{
+				"removes the second value",
+				[]string{"1 2 nip"},
+				[]int{2},
+			},
+			{
+				"leaves values below the top two alone",
+				[]string{"0 1 2 nip"},
+				[]int{0, 2},
+			},
+			{
+				"errors if there is only one value on the stack",
+				[]string{"1 nip"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "tuck",
+		tests: []testCase{
+			{
+				"copies the top value below the second",
+				[]string{"1 2 tuck"},
+				[]int{2, 1, 2},
+			},
+			{
+				"errors if there is only one value on the stack",
+				[]string{"1 tuck"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "pick",
+		tests: []testCase{
+			{
+				"0 pick copies the top value",
+				[]string{"1 2 0 pick"},
+				[]int{1, 2, 2},
+			},
+			{
+				"1 pick copies the second value",
+				[]string{"1 2 1 pick"},
+				[]int{1, 2, 1},
+			},
+			{
+				"copies a deep value",
+				[]string{"1 2 3 4 3 pick"},
+				[]int{1, 2, 3, 4, 1},
+			},
+			{
+				"errors if the index is deeper than the stack",
+				[]string{"1 2 2 pick"},
+				[]int(nil),
+			},
+			{
+				"errors if the index is negative",
+				[]string{"1 2 -1 pick"},
+				[]int(nil),
+			},
+			{
+				"errors if there is nothing on the stack",
+				[]string{"pick"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "roll",
+		tests: []testCase{
+			{
+				"2 roll is rot",
+				[]string{"1 2 3 2 roll"},
+				[]int{2, 3, 1},
+			},
+			{
+				"1 roll is swap",
+				[]string{"1 2 1 roll"},
+				[]int{2, 1},
+			},
+			{
+				"0 roll does nothing",
+				[]string{"1 2 0 roll"},
+				[]int{1, 2},
+			},
+			{
+				"moves a deep value to the top",
+				[]string{"1 2 3 4 3 roll"},
+				[]int{2, 3, 4, 1},
+			},
+			{
+				"errors if the index is deeper than the stack",
+				[]string{"1 2 2 roll"},
+				[]int(nil),
+			},
+			{
+				"errors if the index is negative",
+				[]string{"1 2 -1 roll"},
+				[]int(nil),
+			},
+			{
+				"errors if there is nothing on the stack",
+				[]string{"roll"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "2dup",
+		tests: []testCase{
+			{
+				"copies the top two values",
+				[]string{"1 2 2dup"},
+				[]int{1, 2, 1, 2},
+			},
+			{
+				"errors if there is only one value on the stack",
+				[]string{"1 2dup"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "2drop",
+		tests: []testCase{
+			{
+				"removes the top two values",
+				[]string{"1 2 3 2drop"},
+				[]int{1},
+			},
+			{
+				"errors if there is only one value on the stack",
+				[]string{"1 2drop"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "2swap",
+		tests: []testCase{
+			{
+				"swaps the top two pairs",
+				[]string{"1 2 3 4 2swap"},
+				[]int{3, 4, 1, 2},
+			},
+			{
+				"leaves values below the top four alone",
+				[]string{"0 1 2 3 4 2swap"},
+				[]int{0, 3, 4, 1, 2},
+			},
+			{
+				"errors if there are only three values on the stack",
+				[]string{"1 2 3 2swap"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "depth",
+		tests: []testCase{
+			{
+				"pushes zero for an empty stack",
+				[]string{"depth"},
+				[]int{0},
+			},
+			{
+				"pushes the number of values on the stack",
+				[]string{"5 6 7 depth"},
+				[]int{5, 6, 7, 3},
+			},
+		},
+	},
+}
+
+func TestStackWords(t *testing.T) {
+	runTestGroups(t, stackWordTestGroups)
+}
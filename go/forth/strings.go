@@ -0,0 +1,97 @@
+package forth
+
+import "fmt"
+
+// compileStringLiteral stores the text of an S" literal in memory, one
+// character per cell, and returns an operation that pushes its address and
+// length. The text is stored once, when it is compiled, so a word reuses
+// the same cells every time it runs.
+func (in *Interpreter) compileStringLiteral(text string) (operation, error) {
+	address, length, err := in.storeString(text)
+	if err != nil {
+		return nil, err
+	}
+	return func(in *Interpreter) error {
+		return in.stk.Push(address, length)
+	}, nil
+}
+
+// interpretWord is compileWord for a word that runs outside of a
+// definition, where S" stores its string in the transient buffer.
+func (in *Interpreter) interpretWord(t token) (operation, error) {
+	if t.unclosed || in.fold(t.text) != `s"` {
+		return in.compileWord(t)
+	}
+	address, length, err := in.transientString(t.quoted)
+	if err != nil {
+		return nil, err
+	}
+	return func(in *Interpreter) error {
+		return in.stk.Push(address, length)
+	}, nil
+}
+
+// transientString stores text in the transient buffer, one character per
+// cell, returning its address and length. As in standard forth, S"
+// outside of a definition stores its string there rather than in memory,
+// so that strings typed at a prompt don't use memory up. The buffer is
+// emptied at the start of each evaluation, which is as long as the string
+// lasts. It holds up to as many cells as memory does.
+func (in *Interpreter) transientString(text string) (int, int, error) {
+	runes := []rune(text)
+	if len(in.transient)+len(runes) > in.memorySize {
+		return 0, 0, ErrOutOfMemory
+	}
+	address := in.memorySize + len(in.transient)
+	for _, r := range runes {
+		in.transient = append(in.transient, int(r))
+	}
+	return address, len(runes), nil
+}
+
+// storeString stores text in memory, one character per cell, returning
+// its address and length.
+func (in *Interpreter) storeString(text string) (int, int, error) {
+	runes := []rune(text)
+	if len(in.memory)+len(runes) > in.memorySize {
+		return 0, 0, ErrOutOfMemory
+	}
+	address := len(in.memory)
+	for _, r := range runes {
+		in.memory = append(in.memory, int(r))
+	}
+	return address, len(runes), nil
+}
+
+// pushChar parses `CHAR word`, pushing the code of the first character of
+// word.
+func (in *Interpreter) pushChar(index *int, lines []token) error {
+	code, err := parsedChar(index, lines)
+	if err != nil {
+		return err
+	}
+	return in.stk.Push(code)
+}
+
+// compileChar compiles `[CHAR] word` inside a definition into an operation
+// that pushes the code of the first character of word.
+func compileChar(tokens []token, pos *int) (operation, error) {
+	code, err := parsedChar(pos, tokens)
+	if err != nil {
+		return nil, err
+	}
+	return func(in *Interpreter) error {
+		return in.stk.Push(code)
+	}, nil
+}
+
+// parsedChar reads the word that follows the word at *index, advances
+// *index to it and returns the code of its first character. Unlike names,
+// the word keeps its case, so CHAR A and CHAR a differ.
+func parsedChar(index *int, lines []token) (int, error) {
+	if *index+1 >= len(lines) {
+		return 0, fmt.Errorf("%s %w", lines[*index].text, ErrMissingCharacter)
+	}
+	*index++
+	return int([]rune(lines[*index].text)[0]), nil
+}
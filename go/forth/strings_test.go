@@ -0,0 +1,238 @@
+package forth
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var stringTestGroups = []testGroup{
+	{
+		group: "s\"",
+		tests: []testCase{
+			{
+				"pushes the address and length of a string",
+				[]string{`s" hello"`},
+				[]int{DefaultMemorySize, 5},
+			},
+			{
+				"stores each string after the last in the transient buffer",
+				[]string{`variable x s" ab" s" cde"`},
+				[]int{DefaultMemorySize, 2, DefaultMemorySize + 2, 3},
+			},
+			{
+				"doesn't use memory outside a definition",
+				[]string{`s" ab" 2drop variable x x`},
+				[]int{0},
+			},
+			{
+				"uses memory inside a definition",
+				[]string{`: f s" ab" ;`, "variable x x"},
+				[]int{2},
+			},
+			{
+				"stores a definition's strings when it is made",
+				[]string{`: greet s" hi" ;`, "variable x", "greet 2drop", "forget x", "variable y 99 y !", "greet drop @"},
+				[]int{'h'},
+			},
+			{
+				"stores one character per cell",
+				[]string{`s" hi" drop dup @ swap 1 + @`},
+				[]int{'h', 'i'},
+			},
+			{
+				"counts characters, not bytes",
+				[]string{`s" héllo" nip`},
+				[]int{5},
+			},
+			{
+				"keeps whitespace inside the string",
+				[]string{`s"  a  b " nip`},
+				[]int{6},
+			},
+			{
+				"is case-insensitive",
+				[]string{`S" hi" nip`},
+				[]int{2},
+			},
+			{
+				"pushes the same string each time a word runs",
+				[]string{`: greeting s" hi" ;`, "greeting greeting"},
+				[]int{0, 2, 0, 2},
+			},
+			{
+				"errors without a closing quote",
+				[]string{`s" oops`},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "char",
+		tests: []testCase{
+			{
+				"pushes the code of the first character of the next word",
+				[]string{"char A char hello"},
+				[]int{'A', 'h'},
+			},
+			{
+				"keeps the case of the character",
+				[]string{"CHAR a"},
+				[]int{'a'},
+			},
+			{
+				"reads characters that would otherwise be words",
+				[]string{"char : char 1"},
+				[]int{':', '1'},
+			},
+			{
+				"errors without a following word",
+				[]string{"char"},
+				[]int(nil),
+			},
+			{
+				"errors inside a definition",
+				[]string{": f char a ;"},
+				[]int(nil),
+			},
+		},
+	},
+	{
+		group: "[char]",
+		tests: []testCase{
+			{
+				"compiles the code of the first character of the next word",
+				[]string{": f [char] A [CHAR] z ;", "f"},
+				[]int{'A', 'z'},
+			},
+			{
+				"errors without a following word",
+				[]string{": f [char] ;"},
+				[]int(nil),
+			},
+			{
+				"errors outside a definition",
+				[]string{"[char] a"},
+				[]int(nil),
+			},
+		},
+	},
+}
+
+func TestStringWords(t *testing.T) {
+	runTestGroups(t, stringTestGroups)
+}
+
+func TestType(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       []string
+		output      string
+		stack       []int
+	}{
+		{"prints a string", []string{`s" Hello, World!" type`}, "Hello, World!", []int{}},
+		{"prints part of a string", []string{`s" Hello" 2 - swap 1 + swap type`}, "ell", []int{}},
+		{"prints a string from a definition", []string{`: greet s" hi " type ;`, "greet greet"}, "hi hi ", []int{}},
+		{"prints characters stored with !", []string{"variable x char Z x ! x 1 type"}, "Z", []int{}},
+		{"prints nothing for an empty string", []string{`s" " type`}, "", []int{}},
+	} {
+		var out bytes.Buffer
+		in := New(WithOutput(&out))
+		for _, line := range tc.input {
+			if err := in.Eval(line); err != nil {
+				t.Fatalf("FAIL: %s\n\tEval(%q) returned an error: %q", tc.description, line, err)
+			}
+		}
+		if out.String() != tc.output {
+			t.Fatalf("FAIL: %s\n\t%#v expected output %q, got %q", tc.description, tc.input, tc.output, out.String())
+		}
+		if v := in.Stack(); !reflect.DeepEqual(v, tc.stack) {
+			t.Fatalf("FAIL: %s\n\t%#v expected stack %v, got %v", tc.description, tc.input, tc.stack, v)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       []string
+		target      error
+	}{
+		{"type errors without a length", []string{"type"}, ErrStackUnderflow},
+		{"type errors past the end of memory", []string{`s" hi" 1 + type`}, ErrInvalidAddress},
+		{"char errors without a following word", []string{"char"}, ErrMissingCharacter},
+		{"[char] errors outside a definition", []string{"[char] a"}, ErrCompileOnly},
+		{"char errors inside a definition", []string{": f char a ;"}, ErrInterpretOnly},
+	} {
+		if _, err := Forth(tc.input); !errors.Is(err, tc.target) {
+			t.Fatalf("FAIL: %s\n\tForth(%#v) expected errors.Is(err, %q), got %v",
+				tc.description, tc.input, tc.target, err)
+		}
+		t.Logf("PASS: %s", tc.description)
+	}
+}
+
+func TestStringsNeedMemory(t *testing.T) {
+	in := New(WithMemorySize(4))
+	if err := in.Eval(`: f s" four" ;`); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	if err := in.Eval(`: g s" x" ;`); !errors.Is(err, ErrOutOfMemory) {
+		t.Fatalf("expected ErrOutOfMemory, got %v", err)
+	}
+	if err := in.Eval(`s" four" s" x"`); !errors.Is(err, ErrOutOfMemory) {
+		t.Fatalf("expected the transient buffer to run out, got %v", err)
+	}
+}
+
+func TestDefinitionsFreeTheirStrings(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		input       []string // all but the last line may fail
+	}{
+		{"forgetting a word frees its strings", []string{`: f s" four" ;`, "forget f", `: g s" four" ;`}},
+		{"a failed definition frees its strings", []string{`: f s" four" nope ;`, `: g s" four" ;`}},
+		{"a definition that runs out of memory frees its strings", []string{`: f s" ab" s" cde" ;`, `: g s" four" ;`}},
+	} {
+		for _, how := range []string{"Eval", "Run"} {
+			in := New(WithMemorySize(4))
+			var err error
+			for _, line := range tc.input {
+				if how == "Eval" {
+					err = in.Eval(line)
+				} else if p, compileErr := Compile([]string{line}); compileErr != nil {
+					err = compileErr
+				} else {
+					err = in.Run(p)
+				}
+			}
+			if err != nil {
+				t.Fatalf("FAIL: %s | %s\n\t%#v returned an error: %q", how, tc.description, tc.input, err)
+			}
+			t.Logf("PASS: %s | %s", how, tc.description)
+		}
+	}
+}
+
+func TestTransientStringsAreReused(t *testing.T) {
+	var out bytes.Buffer
+	in := New(WithMemorySize(100), WithOutput(&out))
+	for i := 0; i < 1000; i++ {
+		out.Reset()
+		if err := in.Eval(`s" hello" type`); err != nil {
+			t.Fatalf("Eval %d returned an error: %q", i, err)
+		}
+		if out.String() != "hello" {
+			t.Fatalf("Eval %d printed %q, expected %q", i, out.String(), "hello")
+		}
+	}
+
+	if err := in.Eval(`s" gone"`); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	if err := in.Eval("type"); !errors.Is(err, ErrInvalidAddress) {
+		t.Fatalf("expected a string from an earlier Eval to be gone, got %v", err)
+	}
+}
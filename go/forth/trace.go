@@ -0,0 +1,45 @@
+package forth
+
+// TraceEvent describes a word that is about to execute.
+type TraceEvent struct {
+	Word     string // as it was spelled in the source
+	File     string // the included file the word was read from, if any
+	Position int    // index of the word's token in the code it was read from
+	Line     int
+	Column   int
+	Stack    []int // a copy of the stack, bottom value first
+}
+
+// WithTrace calls fn before each word executes, including the words inside
+// user-defined words each time they run. A word in a definition reports
+// where it was written, which may be in code evaluated earlier.
+func WithTrace(fn func(TraceEvent)) Option {
+	return func(in *Interpreter) {
+		in.trace = fn
+	}
+}
+
+// traced wraps op, the word at tokens[position], so that it reports a
+// TraceEvent before running. Without a trace callback op is returned as is.
+func (in *Interpreter) traced(op operation, tokens []token, position int) operation {
+	if in.trace == nil {
+		return op
+	}
+	return func(in *Interpreter) error {
+		in.traceToken(tokens, position)
+		return op(in)
+	}
+}
+
+// traceToken reports a TraceEvent for the word at tokens[position].
+func (in *Interpreter) traceToken(tokens []token, position int) {
+	t := tokens[position]
+	in.trace(TraceEvent{
+		Word:     t.text,
+		File:     t.file,
+		Position: position,
+		Line:     t.line,
+		Column:   t.column,
+		Stack:    in.Stack(),
+	})
+}
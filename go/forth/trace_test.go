@@ -0,0 +1,57 @@
+package forth
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	var events []TraceEvent
+	in := New(WithTrace(func(e TraceEvent) {
+		events = append(events, e)
+	}))
+	for _, line := range []string{": SQ dup * ;", "3 sq\n1 +"} {
+		if err := in.Eval(line); err != nil {
+			t.Fatalf("Eval(%q) returned an error: %q", line, err)
+		}
+	}
+	expected := []TraceEvent{
+		{Word: "3", Position: 0, Line: 1, Column: 1, Stack: []int{}},
+		{Word: "sq", Position: 1, Line: 1, Column: 3, Stack: []int{3}},
+		{Word: "dup", Position: 2, Line: 1, Column: 6, Stack: []int{3}},
+		{Word: "*", Position: 3, Line: 1, Column: 10, Stack: []int{3, 3}},
+		{Word: "1", Position: 2, Line: 2, Column: 1, Stack: []int{9}},
+		{Word: "+", Position: 3, Line: 2, Column: 3, Stack: []int{9, 1}},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected trace\n\t%v\ngot\n\t%v", expected, events)
+	}
+}
+
+func TestTraceControlWords(t *testing.T) {
+	var words []string
+	in := New(WithTrace(func(e TraceEvent) {
+		words = append(words, e.Word)
+	}))
+	if err := in.Eval(": f 2 0 do i if 7 then loop ; f"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	expected := []string{"f", "2", "0", "do", "i", "if", "i", "if", "7"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Fatalf("expected words %v, got %v", expected, words)
+	}
+}
+
+func TestTraceSnapshotsTheStack(t *testing.T) {
+	var snapshots [][]int
+	in := New(WithTrace(func(e TraceEvent) {
+		snapshots = append(snapshots, e.Stack)
+	}))
+	if err := in.Eval("1 2 +"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	expected := [][]int{{}, {1}, {1, 2}}
+	if !reflect.DeepEqual(snapshots, expected) {
+		t.Fatalf("expected snapshots %v, got %v", expected, snapshots)
+	}
+}
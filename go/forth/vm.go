@@ -0,0 +1,209 @@
+package forth
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+// Run executes a compiled Program against the current session, just as
+// Eval would execute the code it was compiled from.
+func (in *Interpreter) Run(p *Program) error {
+	return in.RunContext(context.Background(), p)
+}
+
+// RunContext is like Run, but stops with the context's error as soon as
+// ctx is done.
+func (in *Interpreter) RunContext(ctx context.Context, p *Program) error {
+	defer in.watch(ctx)()
+	m := &machine{
+		p:      p,
+		defs:   make([]operation, len(p.defs)),
+		stored: make([]storedString, len(p.strings)),
+	}
+	return m.exec(in, p.main, nil, true)
+}
+
+// machine holds the state of a single run of a Program.
+type machine struct {
+	p      *Program
+	defs   []operation    // p.defs, once they have been defined
+	stored []storedString // p.strings, once the definitions using them are made
+}
+
+// storedString is where a run stored one of a Program's S" strings.
+type storedString struct {
+	address, length int
+}
+
+// exec runs a block of code. A definition's code calls the words in links,
+// which holds what each of its opCall instructions was bound to when it
+// was defined. The program's main code looks each word up as it runs it
+// instead. Errors from the main code record the index of the token where
+// evaluation failed, as they do from Eval.
+func (m *machine) exec(in *Interpreter, code []instruction, links []operation, main bool) (err error) {
+	loops := len(in.loops)
+	pc := 0
+	for pc < len(code) {
+		ins := code[pc]
+		pc++
+		// As Eval does, fail at an unknown word before counting a step or
+		// reporting it to WithTrace.
+		var op operation
+		if ins.op == opCall {
+			if !main {
+				op = links[pc-1]
+			} else if op = m.lookup(in, ins.arg); op == nil {
+				err = &UnknownWordError{Word: m.p.tokens[ins.pos].text}
+			}
+		}
+		if err == nil {
+			err = in.step()
+		}
+		if err == nil {
+			if in.trace != nil && !ins.quiet {
+				in.traceToken(m.p.tokens, ins.pos)
+			}
+			switch ins.op {
+			case opPush:
+				err = in.stk.Push(ins.arg)
+			case opPushBig:
+				err = in.stk.PushBig(m.p.bigs[ins.arg])
+			case opCall:
+				err = op(in)
+			case opCallDef:
+				if op := m.defs[ins.arg]; op != nil {
+					err = op(in)
+				} else {
+					err = &UnknownWordError{Word: m.p.defs[ins.arg].name}
+				}
+			case opDefine:
+				err = m.define(in, ins.arg, &ins.pos)
+			case opBranch:
+				pc = ins.arg
+			case opBranchIfFalse:
+				if in.stk.Len() < 1 {
+					word := strings.ToLower(m.p.tokens[ins.pos].text)
+					err = underflow(word + " needs a flag on the stack")
+					break
+				}
+				zero := in.stk.isZero(0)
+				in.stk.Pop()
+				if zero {
+					pc = ins.arg
+				}
+			case opDo:
+				err = doOp(in)
+			case opLoop:
+				if len(in.loops) <= loops {
+					err = ErrNotInLoop
+					break
+				}
+				frame := &in.loops[len(in.loops)-1]
+				frame.index++
+				if frame.index < frame.limit {
+					pc = ins.arg
+				} else {
+					in.loops = in.loops[:len(in.loops)-1]
+				}
+			case opPrint:
+				_, err = io.WriteString(in.out, m.p.strings[ins.arg])
+			case opString:
+				if main {
+					err = m.pushTransient(in, ins.arg)
+				} else {
+					s := m.stored[ins.arg]
+					err = in.stk.Push(s.address, s.length)
+				}
+			case opSee:
+				var text string
+				if text, err = in.See(m.p.names[ins.arg]); err == nil {
+					_, err = io.WriteString(in.out, text+"\n")
+				}
+			case opForget:
+				err = in.Forget(m.p.names[ins.arg])
+			case opInclude:
+				err = in.include(m.p.tokens[ins.pos].file, m.p.names[ins.arg])
+			}
+		}
+		if err != nil {
+			if main {
+				err = positioned(err, ins.pos, m.p.tokens)
+			}
+			break
+		}
+	}
+	in.loops = in.loops[:loops]
+	return err
+}
+
+// lookup finds the operation names[i] currently means, as compileWord
+// would, or returns nil if the word is unknown.
+func (m *machine) lookup(in *Interpreter, i int) operation {
+	name := m.p.names[i]
+	if op, ok := compiledOps[name]; ok {
+		return op
+	}
+	op, _ := in.lookup(name)
+	return op
+}
+
+// define adds defs[i] to the interpreter's dictionary, where later Evals
+// and Runs can find it too. Before a colon definition is made, every word
+// its body calls by name is looked up and every S" string it uses is
+// stored in memory, in order, as Eval does when it compiles a definition.
+// So the body can't call the word it defines, or any word defined after
+// it, and
+// its strings are freed when it is forgotten, or if it can't be made. If
+// this fails at a word of the body, define sets pos to the index of its
+// token.
+func (m *machine) define(in *Interpreter, i int, pos *int) error {
+	def := m.p.defs[i]
+	var op operation
+	var err error
+	switch def.kind {
+	case colonDef:
+		here := len(in.memory)
+		links := make([]operation, len(def.code))
+		for j, ins := range def.code {
+			switch ins.op {
+			case opCall:
+				if links[j] = m.lookup(in, ins.arg); links[j] == nil {
+					in.memory = in.memory[:here]
+					*pos = ins.pos
+					return &UnknownWordError{Word: m.p.tokens[ins.pos].text}
+				}
+			case opString:
+				address, length, err := in.storeString(m.p.strings[ins.arg])
+				if err != nil {
+					in.memory = in.memory[:here]
+					*pos = ins.pos
+					return err
+				}
+				m.stored[ins.arg] = storedString{address: address, length: length}
+			}
+		}
+		op = func(in *Interpreter) error {
+			return in.callWord(func() error {
+				return m.exec(in, def.code, links, false)
+			})
+		}
+		in.define(def.name, op, def.source, here)
+	case variableDef:
+		op, err = in.variable(def.name)
+	case constantDef:
+		op, err = in.constant(def.name)
+	}
+	m.defs[i] = op
+	return err
+}
+
+// pushTransient pushes the address and length of strings[i], stored in the
+// transient buffer, as S" outside of a definition does.
+func (m *machine) pushTransient(in *Interpreter, i int) error {
+	address, length, err := in.transientString(m.p.strings[i])
+	if err != nil {
+		return err
+	}
+	return in.stk.Push(address, length)
+}
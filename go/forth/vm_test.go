@@ -0,0 +1,286 @@
+package forth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// compiledTestGroups are the groups whose cases must behave the same when
+// compiled and run as when evaluated.
+var compiledTestGroups = [][]testGroup{
+	testGroups, controlTestGroups, compareTestGroups, memoryTestGroups,
+	dictionaryTestGroups, returnStackTestGroups, stackWordTestGroups,
+	arithmeticTestGroups, stringTestGroups,
+}
+
+// forthCompiled is Forth, compiled to bytecode and run in the given mode.
+func forthCompiled(mode Mode, codeText []string) ([]int, error) {
+	p, err := Compile(codeText)
+	if err != nil {
+		return nil, err
+	}
+	in := New(WithMode(mode))
+	err = in.Run(p)
+	return in.Stack(), err
+}
+
+func TestCompiledForth(t *testing.T) {
+	for _, groups := range compiledTestGroups {
+		for _, mode := range modes {
+			for _, tg := range groups {
+				for _, tc := range tg.tests {
+					v, err := forthCompiled(mode, tc.input)
+					if err == nil && tc.expected == nil {
+						t.Fatalf("FAIL: %s | %s | %s mode\n\tcompiled %#v expected an error, got %v",
+							tg.group, tc.description, mode, tc.input, v)
+					} else if err != nil && tc.expected != nil {
+						t.Fatalf("FAIL: %s | %s | %s mode\n\tcompiled %#v expected %v, got an error: %q",
+							tg.group, tc.description, mode, tc.input, tc.expected, err)
+					} else if err == nil && !reflect.DeepEqual(v, tc.expected) {
+						t.Fatalf("FAIL: %s | %s | %s mode\n\tcompiled %#v expected %v, got %v",
+							tg.group, tc.description, mode, tc.input, tc.expected, v)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRunSharesTheSession(t *testing.T) {
+	var out bytes.Buffer
+	in := New(WithOutput(&out))
+	if err := in.Eval(": sq dup * ;"); err != nil {
+		t.Fatalf("Eval returned an error: %q", err)
+	}
+	p, err := Compile([]string{": cube dup sq * ;", "3 cube ."})
+	if err != nil {
+		t.Fatalf("Compile returned an error: %q", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := in.Run(p); err != nil {
+			t.Fatalf("Run returned an error: %q", err)
+		}
+	}
+	if err := in.Eval("2 cube . see cube"); err != nil {
+		t.Fatalf("Eval after Run returned an error: %q", err)
+	}
+	if expected := "27 27 8 : cube dup sq * ;\n"; out.String() != expected {
+		t.Fatalf("expected output %q, got %q", expected, out.String())
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	p, err := Compile([]string{": f 1 0 / ;", "1 2", "f"})
+	if err != nil {
+		t.Fatalf("Compile returned an error: %q", err)
+	}
+	in := New()
+	err = in.Run(p)
+	var evalErr *EvalError
+	if !errors.As(err, &evalErr) || !errors.Is(err, ErrDivideByZero) {
+		t.Fatalf("expected an *EvalError wrapping ErrDivideByZero, got %v", err)
+	}
+	if evalErr.Position != 8 || evalErr.Line != 3 || evalErr.Column != 1 {
+		t.Fatalf("expected position 8 at line 3, column 1, got %d at line %d, column %d",
+			evalErr.Position, evalErr.Line, evalErr.Column)
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{1, 2}) {
+		t.Fatalf("expected the stack to keep [1 2], got %v", v)
+	}
+
+	if _, err := Compile([]string{"1 +", ": f if ;"}); !errors.Is(err, ErrUnbalancedControl) {
+		t.Fatalf("expected Compile to fail with ErrUnbalancedControl, got %v", err)
+	}
+	p, err = Compile([]string{"1 2 nope"})
+	if err != nil {
+		t.Fatalf("Compile returned an error for an unknown word: %q", err)
+	}
+	var unknown *UnknownWordError
+	if err := New().Run(p); !errors.As(err, &unknown) || unknown.Word != "nope" {
+		t.Fatalf("expected Run to fail with an UnknownWordError for nope, got %v", err)
+	}
+}
+
+func TestRunBindsDefinitions(t *testing.T) {
+	p, err := Compile([]string{": dup 0 dup ;", "1 dup"})
+	if err != nil {
+		t.Fatalf("Compile returned an error: %q", err)
+	}
+	in := New()
+	if err := in.Run(p); err != nil {
+		t.Fatalf("Run returned an error: %q", err)
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{1, 0, 0}) {
+		t.Fatalf("expected the definition to call the built-in dup, got %v", v)
+	}
+
+	p, err = Compile([]string{": f nope ;", ": nope 1 ;"})
+	if err != nil {
+		t.Fatalf("Compile returned an error: %q", err)
+	}
+	in = New()
+	var unknown *UnknownWordError
+	if err := in.Run(p); !errors.As(err, &unknown) || unknown.Word != "nope" || unknown.Position != 2 {
+		t.Fatalf("expected Run to fail with an UnknownWordError for nope at position 2, got %v", err)
+	}
+	if _, err := in.See("f"); err == nil {
+		t.Fatalf("expected f not to be defined")
+	}
+
+	fsys := fstest.MapFS{"r.fs": {Data: []byte(": x 99 ;\n")}}
+	for _, tc := range []struct {
+		description string
+		input       []string
+		expected    []int // nil slice indicates error expected.
+	}{
+		{"looks up a redefined word again", []string{": x 1 ;", "x include r.fs x"}, []int{1, 99}},
+		{"doesn't call a forgotten word", []string{": x 1 ;", "x forget x x"}, nil},
+		{"binds a definition when it is made", []string{": x 1 ;", ": f x ;", "include r.fs f x"}, []int{1, 99}},
+	} {
+		in := New(WithFS(fsys))
+		err := in.evalContext(context.Background(), lex(tc.input))
+		checkCase(t, "Eval", tc.description, tc.input, tc.expected, in.Stack(), err)
+
+		in = New(WithFS(fsys))
+		p, err := Compile(tc.input)
+		if err == nil {
+			err = in.Run(p)
+		}
+		checkCase(t, "Compile", tc.description, tc.input, tc.expected, in.Stack(), err)
+	}
+}
+
+func TestRunUsesRegisteredWords(t *testing.T) {
+	p, err := Compile([]string{": twice sensor 2 * ;", "twice"})
+	if err != nil {
+		t.Fatalf("Compile returned an error: %q", err)
+	}
+	in := New()
+	in.RegisterWord("sensor", func(in *Interpreter) error {
+		return in.Push(21)
+	})
+	if err := in.Run(p); err != nil {
+		t.Fatalf("Run returned an error: %q", err)
+	}
+	if v := in.Stack(); !reflect.DeepEqual(v, []int{42}) {
+		t.Fatalf("expected [42], got %v", v)
+	}
+}
+
+func TestRunKeepsLimits(t *testing.T) {
+	p, err := Compile([]string{": forever begin 0 until ;", "forever"})
+	if err != nil {
+		t.Fatalf("Compile returned an error: %q", err)
+	}
+	if err := New(WithMaxSteps(1000)).Run(p); !errors.Is(err, ErrStepLimit) {
+		t.Fatalf("expected ErrStepLimit, got %v", err)
+	}
+	p, err = Compile([]string{": deep recurse ;", "deep"})
+	if err != nil {
+		t.Fatalf("Compile returned an error: %q", err)
+	}
+	if err := New().Run(p); !errors.Is(err, ErrCallDepthLimit) {
+		t.Fatalf("expected ErrCallDepthLimit, got %v", err)
+	}
+}
+
+func TestRunTraces(t *testing.T) {
+	var words []string
+	in := New(WithTrace(func(e TraceEvent) {
+		words = append(words, e.Word)
+	}))
+	p, err := Compile([]string{": f 2 0 do i if 7 then loop ; f"})
+	if err != nil {
+		t.Fatalf("Compile returned an error: %q", err)
+	}
+	if err := in.Run(p); err != nil {
+		t.Fatalf("Run returned an error: %q", err)
+	}
+	expected := []string{"f", "2", "0", "do", "i", "if", "i", "if", "7"}
+	if !reflect.DeepEqual(words, expected) {
+		t.Fatalf("expected words %v, got %v", expected, words)
+	}
+}
+
+func TestRunTracesLikeEval(t *testing.T) {
+	for _, groups := range compiledTestGroups {
+		for _, tg := range groups {
+			for _, tc := range tg.tests {
+				p, err := Compile(tc.input)
+				if err != nil {
+					continue
+				}
+				var evaluated, run []TraceEvent
+				New(WithTrace(func(e TraceEvent) {
+					evaluated = append(evaluated, e)
+				})).evalContext(context.Background(), lex(tc.input))
+				New(WithTrace(func(e TraceEvent) {
+					run = append(run, e)
+				})).Run(p)
+				if !reflect.DeepEqual(evaluated, run) {
+					t.Fatalf("FAIL: %s | %s\n\t%#v traced differently when compiled:\n\t%v\n\t%v",
+						tg.group, tc.description, tc.input, evaluated, run)
+				}
+			}
+		}
+	}
+}
+
+// loopProgram spends its time calling words rather than parsing them.
+var loopProgram = []string{
+	": sq dup * ;",
+	": sum-squares 0 swap 0 do i sq + loop ;",
+	"1000 sum-squares drop",
+}
+
+func BenchmarkEvalLoop(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Forth(loopProgram)
+	}
+}
+
+func BenchmarkRunLoop(b *testing.B) {
+	p, err := Compile(loopProgram)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		New().Run(p)
+	}
+}
+
+// straightLine is a line with no definitions, so evaluating it spends its
+// time lexing and parsing.
+const straightLine = "1 2 + 3 * dup 4 - swap over * drop drop 10 20 30 rot -rot 2drop drop"
+
+func BenchmarkEvalLine(b *testing.B) {
+	in := New()
+	for i := 0; i < b.N; i++ {
+		in.Eval(straightLine)
+	}
+}
+
+func BenchmarkRunLine(b *testing.B) {
+	p, err := Compile([]string{straightLine})
+	if err != nil {
+		b.Fatal(err)
+	}
+	in := New()
+	for i := 0; i < b.N; i++ {
+		in.Run(p)
+	}
+}
+
+func BenchmarkCompiledForth(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, tg := range testGroups {
+			for _, tc := range tg.tests {
+				forthCompiled(WrapMode, tc.input)
+			}
+		}
+	}
+}